@@ -149,13 +149,16 @@
       // Set up playground for this example.
       var setup = function() {
         var code = $('.code', el);
+        // Go+ examples name the Go+ playground; Go examples use this site.
+        var base = $(el).attr('data-playground') || '';
         playground({
           codeEl: code,
           outputEl: $('.output', el),
           runEl: $('.run', el),
           fmtEl: $('.fmt', el),
           shareEl: $('.share', el),
-          shareRedirect: '//play.golang.org/p/',
+          shareRedirect: base ? base + '/p/' : '//play.golang.org/p/',
+          baseURL: base,
         });
 
         // Make the code textarea resize to fit content.
@@ -190,6 +193,22 @@
     });
   }
 
+  // setupTabs makes the tabs in each tab set generated from Markdown
+  // show their panels when clicked.
+  function setupTabs() {
+    $('.js-tabs').each(function(i, el) {
+      var tabs = $(el).children('.TabSection-tabList').children('[role=tab]');
+      var panels = $(el).children('[role=tabpanel]');
+      tabs.click(function() {
+        var n = tabs.index(this);
+        tabs.attr('aria-selected', 'false').attr('tabindex', '-1');
+        $(this).attr('aria-selected', 'true').attr('tabindex', '0');
+        panels.attr('hidden', 'hidden');
+        panels.eq(n).removeAttr('hidden');
+      });
+    });
+  }
+
   // fixFocus tries to put focus to #page so that keyboard navigation works.
   function fixFocus() {
     var page = $('#page');
@@ -368,6 +387,7 @@
     bindToggleLinks('.examplesLink', '');
     bindToggleLinks('.indexLink', '');
     setupInlinePlayground();
+    setupTabs();
     fixFocus();
     toggleHash();
     personalizeInstallInstructions();
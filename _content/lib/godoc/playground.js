@@ -43,8 +43,11 @@ here's a skeleton implementation of a playground transport.
 // HTTPTransport is the default transport.
 // enableVet enables running vet if a program was compiled and ran successfully.
 // If vet returned any errors, display them before the output of a program.
-function HTTPTransport(enableVet) {
+// baseURL is the URL of the playground backend serving /compile and /vet
+// (default is this site).
+function HTTPTransport(enableVet, baseURL) {
   'use strict';
+  baseURL = baseURL || '';
 
   function playback(output, data) {
     // Backwards compatibility: default values do not affect the output.
@@ -123,7 +126,7 @@ function HTTPTransport(enableVet) {
       seq++;
       var cur = seq;
       var playing;
-      $.ajax('/compile', {
+      $.ajax(baseURL + '/compile', {
         type: 'POST',
         data: { version: 2, body: body },
         dataType: 'json',
@@ -146,7 +149,7 @@ function HTTPTransport(enableVet) {
             return;
           }
 
-          $.ajax('/vet', {
+          $.ajax(baseURL + '/vet', {
             data: { body: body },
             type: 'POST',
             dataType: 'json',
@@ -318,9 +321,12 @@ function PlaygroundOutput(el) {
   //  transport - playground transport to use (default is HTTPTransport)
   //  enableShortcuts - whether to enable shortcuts (Ctrl+S/Cmd+S to save) (default is false)
   //  enableVet - enable running vet and displaying its errors
+  //  baseURL - URL of the playground backend serving /compile, /fmt, and /share (default is this site)
   function playground(opts) {
     var code = $(opts.codeEl);
-    var transport = opts['transport'] || new HTTPTransport(opts['enableVet']);
+    var baseURL = opts['baseURL'] || '';
+    var transport =
+      opts['transport'] || new HTTPTransport(opts['enableVet'], baseURL);
     var running;
 
     // autoindent helpers.
@@ -474,7 +480,7 @@ function PlaygroundOutput(el) {
       if ($(opts.fmtImportEl).is(':checked')) {
         data['imports'] = 'true';
       }
-      $.ajax('/fmt', {
+      $.ajax(baseURL + '/fmt', {
         data: data,
         type: 'POST',
         dataType: 'json',
@@ -499,7 +505,7 @@ function PlaygroundOutput(el) {
       sharing = true;
 
       var sharingData = body();
-      $.ajax('/share', {
+      $.ajax(baseURL + '/share', {
         processData: false,
         data: sharingData,
         type: 'POST',
@@ -0,0 +1,89 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package codewalk
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// An AddressError reports a codewalk step whose address
+// cannot be resolved against the file it refers to.
+type AddressError struct {
+	Codewalk string // codewalk description file, such as doc/codewalk/codewalk.xml
+	Step     string // title of the step
+	File     string // file named by the step's address
+	Addr     string // address within File, without the file name
+	Err      error
+}
+
+func (e *AddressError) Error() string {
+	addr := e.File
+	if e.Addr != "" {
+		addr += ":" + e.Addr
+	}
+	return fmt.Sprintf("%s: step %q: %s: %v", e.Codewalk, e.Step, addr, e.Err)
+}
+
+func (e *AddressError) Unwrap() error { return e.Err }
+
+// Check loads every codewalk description (*.xml) in the directory dir of fsys
+// and resolves the address of each step against the file it names.
+// It returns the problems found, one *AddressError per stale step address,
+// along with any errors reading or parsing the descriptions themselves.
+func Check(fsys fs.FS, dir string) []error {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.xml"))
+	if err != nil {
+		return []error{err}
+	}
+	var errs []error
+	for _, name := range files {
+		cw, err := loadCodewalk(fsys, name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, st := range cw.Step {
+			if st.Err == nil {
+				continue
+			}
+			file, addr, _ := cut(st.Src, ":")
+			errs = append(errs, &AddressError{
+				Codewalk: name,
+				Step:     st.Title,
+				File:     file,
+				Addr:     addr,
+				Err:      st.Err,
+			})
+		}
+	}
+	return errs
+}
+
+// CheckError is like Check but returns a single error listing
+// all the problems found, one per line, or nil if there are none.
+func CheckError(fsys fs.FS, dir string) error {
+	errs := Check(fsys, dir)
+	if len(errs) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	for _, err := range errs {
+		fmt.Fprintf(&buf, "%v\n", err)
+	}
+	return errors.New(strings.TrimSuffix(buf.String(), "\n"))
+}
+
+// cut returns the result of cutting s around the first instance of sep.
+func cut(s, sep string) (before, after string, ok bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
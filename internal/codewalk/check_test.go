@@ -0,0 +1,56 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package codewalk
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCheck(t *testing.T) {
+	fsys := fstest.MapFS{
+		"doc/codewalk/walk.xml": {Data: []byte(`<codewalk title="Walk">
+<step title="Whole file" src="doc/codewalk/hello.go">x</step>
+<step title="Main" src="doc/codewalk/hello.go:/func main/,/}/">x</step>
+<step title="Line" src="doc/codewalk/hello.go:3">x</step>
+<step title="Gone" src="doc/codewalk/hello.go:/func gone/">x</step>
+<step title="Too far" src="doc/codewalk/hello.go:99">x</step>
+<step title="Missing" src="doc/codewalk/missing.go:1">x</step>
+</codewalk>`)},
+		"doc/codewalk/hello.go": {Data: []byte("package main\n\nfunc main() {\n}\n")},
+	}
+
+	errs := Check(fsys, "doc/codewalk")
+	var steps []string
+	for _, err := range errs {
+		var ae *AddressError
+		if !errors.As(err, &ae) {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if ae.Codewalk != "doc/codewalk/walk.xml" {
+			t.Errorf("%v: Codewalk = %q, want doc/codewalk/walk.xml", err, ae.Codewalk)
+		}
+		steps = append(steps, ae.Step+" "+ae.File)
+	}
+	want := []string{
+		"Gone doc/codewalk/hello.go",
+		"Too far doc/codewalk/hello.go",
+		"Missing doc/codewalk/missing.go",
+	}
+	if len(steps) != len(want) {
+		t.Fatalf("Check found %q, want %q", steps, want)
+	}
+	for i := range want {
+		if steps[i] != want[i] {
+			t.Errorf("Check found %q, want %q", steps, want)
+			break
+		}
+	}
+
+	if err := CheckError(fsys, "doc/nothing"); err != nil {
+		t.Errorf("CheckError(empty dir) = %v, want nil", err)
+	}
+}
@@ -28,8 +28,8 @@ import (
 	"strings"
 	"unicode/utf8"
 
-	"golang.org/x/website/internal/backport/html/template"
-	"golang.org/x/website/internal/web"
+	"github.com/goplus/website/internal/backport/html/template"
+	"github.com/goplus/website/internal/web"
 )
 
 type server struct {
@@ -68,7 +68,7 @@ func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Otherwise append .xml and hope to find
 	// a codewalk description, but before trim
 	// the trailing /.
-	cw, err := s.loadCodewalk(relpath + ".xml")
+	cw, err := loadCodewalk(s.fsys, relpath+".xml")
 	if err != nil {
 		log.Print(err)
 		s.site.ServeError(w, r, err)
@@ -143,9 +143,9 @@ func (st *codestep) String() string {
 	return s
 }
 
-// loadCodewalk reads a codewalk from the named XML file.
-func (s *server) loadCodewalk(filename string) (*codewalk, error) {
-	f, err := s.fsys.Open(filename)
+// loadCodewalk reads a codewalk from the named XML file in fsys.
+func loadCodewalk(fsys fs.FS, filename string) (*codewalk, error) {
+	f, err := fsys.Open(filename)
 	if err != nil {
 		return nil, err
 	}
@@ -166,7 +166,7 @@ func (s *server) loadCodewalk(filename string) (*codewalk, error) {
 			i = len(st.Src)
 		}
 		filename := st.Src[0:i]
-		data, err := fs.ReadFile(s.fsys, filename)
+		data, err := fs.ReadFile(fsys, filename)
 		if err != nil {
 			st.Err = err
 			continue
@@ -225,7 +225,7 @@ func (s *server) codewalkDir(w http.ResponseWriter, r *http.Request, relpath str
 		if fi.IsDir() {
 			v = append(v, &elem{name + "/", ""})
 		} else if strings.HasSuffix(name, ".xml") {
-			cw, err := s.loadCodewalk(relpath + "/" + name)
+			cw, err := loadCodewalk(s.fsys, relpath+"/"+name)
 			if err != nil {
 				continue
 			}
@@ -0,0 +1,315 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package linkcheck finds broken links in a web site.
+//
+// A Checker fetches each of a list of pages from the site's http.Handler,
+// extracts every href and src attribute, and checks that each link works.
+// Internal links are resolved by the same handler, so that they are checked
+// against everything the site serves: pages, directories, files, redirects,
+// and generated content like package documentation.
+// A link with a #fragment must also name an element ID (such as the ID
+// of a heading) or anchor name in its target page.
+// External links are checked only when the Checker has an HTTP client.
+package linkcheck
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// maxRedirects is the maximum number of redirects followed for one link.
+const maxRedirects = 10
+
+// A Checker checks the links in a web site.
+// It remembers the result of checking each link target,
+// so that each is only fetched once.
+// A Checker must not be used concurrently.
+type Checker struct {
+	// Handler serves the site being checked.
+	Handler http.Handler
+
+	// Hosts lists host names that refer to the site itself,
+	// such as "goplus.org". Links to these hosts are checked
+	// using Handler, like relative links.
+	Hosts []string
+
+	// Client, if non-nil, is used to check external links.
+	// If Client is nil, external links are not checked.
+	Client *http.Client
+
+	targets  map[string]*target // internal URL (without fragment) -> result of fetching it
+	external map[string]error   // external URL (without fragment) -> result of fetching it
+}
+
+// A target is the result of fetching an internal URL.
+type target struct {
+	err  error           // non-nil if the URL is broken
+	ids  map[string]bool // element IDs and anchor names in the final page
+	html bool            // whether the final page is HTML
+	body []byte          // final page body, if HTML
+	url  *url.URL        // final URL, after redirects
+}
+
+// A Broken describes a broken link.
+type Broken struct {
+	Page string // URL path of the page containing the link
+	Link string // link, as written in the page
+	Err  error  // what is wrong with the link
+}
+
+func (b *Broken) String() string {
+	return fmt.Sprintf("%s: %s: %v", b.Page, b.Link, b.Err)
+}
+
+// Check fetches the pages at the given URL paths and checks their links.
+// It returns the broken links it finds, sorted by page and then link.
+// A page that cannot itself be fetched is reported as a broken link
+// from that page to itself.
+func (c *Checker) Check(pages []string) []*Broken {
+	if c.targets == nil {
+		c.targets = make(map[string]*target)
+		c.external = make(map[string]error)
+	}
+	var broken []*Broken
+	for _, page := range pages {
+		u, err := url.Parse(page)
+		if err != nil {
+			broken = append(broken, &Broken{page, page, err})
+			continue
+		}
+		t := c.fetch(u)
+		if t.err != nil {
+			broken = append(broken, &Broken{page, page, t.err})
+			continue
+		}
+		if !t.html {
+			continue
+		}
+		links, err := extractLinks(t.body)
+		if err != nil {
+			broken = append(broken, &Broken{page, page, err})
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, link := range links {
+			if seen[link] {
+				continue
+			}
+			seen[link] = true
+			if err := c.checkLink(t, link); err != nil {
+				broken = append(broken, &Broken{page, link, err})
+			}
+		}
+	}
+	sort.SliceStable(broken, func(i, j int) bool {
+		if broken[i].Page != broken[j].Page {
+			return broken[i].Page < broken[j].Page
+		}
+		return broken[i].Link < broken[j].Link
+	})
+	return broken
+}
+
+// checkLink checks the link found in the page t.
+func (c *Checker) checkLink(page *target, link string) error {
+	ref, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return err
+	}
+	u := page.url.ResolveReference(ref)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		// Links like mailto:, irc:, and ircs: cannot be checked.
+		return nil
+	}
+
+	if !c.internal(u) {
+		if c.Client == nil {
+			return nil
+		}
+		return c.fetchExternal(u)
+	}
+
+	t := page
+	if u.Path != page.url.Path || u.RawQuery != page.url.RawQuery || u.Host != page.url.Host {
+		t = c.fetch(u)
+	}
+	if t.err != nil {
+		return t.err
+	}
+	if frag := u.Fragment; frag != "" && t.html && !t.ids[frag] {
+		return fmt.Errorf("no element with id %q in %s", frag, t.url.Path)
+	}
+	return nil
+}
+
+// internal reports whether u refers to the site being checked.
+func (c *Checker) internal(u *url.URL) bool {
+	if u.Host == "" || u.Host == "example.com" { // httptest.NewRequest's default host
+		return true
+	}
+	for _, h := range c.Hosts {
+		if u.Host == h {
+			return true
+		}
+	}
+	return false
+}
+
+// fetch fetches the internal URL u using c.Handler, following redirects.
+func (c *Checker) fetch(u *url.URL) *target {
+	key := *u
+	key.Fragment = ""
+	if t := c.targets[key.String()]; t != nil {
+		return t
+	}
+	t := &target{}
+	c.targets[key.String()] = t
+
+	cur := &key
+	if cur.Scheme == "" {
+		cur.Scheme = "http"
+	}
+	if cur.Host == "" {
+		cur.Host = "example.com"
+	}
+	for i := 0; ; i++ {
+		if !c.internal(cur) {
+			// Redirected off site.
+			t.url = cur
+			if c.Client != nil {
+				t.err = c.fetchExternal(cur)
+			}
+			return t
+		}
+		if i > maxRedirects {
+			t.err = fmt.Errorf("too many redirects")
+			return t
+		}
+		req := httptest.NewRequest("GET", cur.String(), nil)
+		w := httptest.NewRecorder()
+		c.Handler.ServeHTTP(w, req)
+		resp := w.Result()
+		switch {
+		case resp.StatusCode >= 300 && resp.StatusCode < 400:
+			loc, err := resp.Location()
+			if err != nil {
+				t.err = fmt.Errorf("redirect (%d) without Location", resp.StatusCode)
+				return t
+			}
+			cur = loc
+			continue
+		case resp.StatusCode != http.StatusOK:
+			t.err = fmt.Errorf("%s", resp.Status)
+			return t
+		}
+		t.url = cur
+		if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+			body, _ := io.ReadAll(resp.Body)
+			t.html = true
+			t.body = body
+			t.ids = extractIDs(body)
+		}
+		return t
+	}
+}
+
+// fetchExternal checks the external URL u using c.Client.
+func (c *Checker) fetchExternal(u *url.URL) error {
+	key := *u
+	key.Fragment = ""
+	if err, ok := c.external[key.String()]; ok {
+		return err
+	}
+	err := c.head(key.String())
+	c.external[key.String()] = err
+	return err
+}
+
+// head checks that the external URL responds successfully,
+// trying a GET if the server does not allow HEAD requests.
+func (c *Checker) head(u string) error {
+	resp, err := c.Client.Head(u)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		resp.Body.Close()
+		resp, err = c.Client.Get(u)
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("%s", resp.Status)
+	}
+	return nil
+}
+
+// extractLinks returns the values of the href and src attributes in the HTML page.
+func extractLinks(page []byte) ([]string, error) {
+	doc, err := html.Parse(bytes.NewReader(page))
+	if err != nil {
+		return nil, err
+	}
+	var links []string
+	walk(doc, func(n *html.Node) {
+		for _, a := range n.Attr {
+			if a.Key == "href" || a.Key == "src" {
+				// <link rel="preconnect"> and the like name hosts, not pages.
+				if n.Data == "link" && (attr(n, "rel") == "preconnect" || attr(n, "rel") == "dns-prefetch") {
+					continue
+				}
+				links = append(links, a.Val)
+			}
+		}
+	})
+	return links, nil
+}
+
+// extractIDs returns the set of element IDs and anchor names in the HTML page.
+func extractIDs(page []byte) map[string]bool {
+	ids := make(map[string]bool)
+	doc, err := html.Parse(bytes.NewReader(page))
+	if err != nil {
+		return ids
+	}
+	walk(doc, func(n *html.Node) {
+		if id := attr(n, "id"); id != "" {
+			ids[id] = true
+		}
+		if n.Data == "a" {
+			if name := attr(n, "name"); name != "" {
+				ids[name] = true
+			}
+		}
+	})
+	return ids
+}
+
+// walk calls f for each element node in the tree rooted at n.
+func walk(n *html.Node, f func(*html.Node)) {
+	if n.Type == html.ElementNode {
+		f(n)
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c, f)
+	}
+}
+
+// attr returns the value of n's attribute with the given key, or "".
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,87 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package linkcheck
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/goplus/website/internal/web"
+)
+
+var testSite = fstest.MapFS{
+	"site.tmpl":  {Data: []byte(`<html><body>{{.Content}}</body></html>`)},
+	"error.tmpl": {Data: []byte(`{{.error}}`)},
+	"index.md": {Data: []byte("# Home\n\n" +
+		"[doc](/doc/) [intro](/doc/intro#setup) [bad anchor](/doc/intro#nope)\n" +
+		"[missing](/doc/missing) [old](/old) [mail](mailto:gopher@example.com) [chat](ircs:irc.libera.chat/go-nuts)\n" +
+		"[top](#home) [ext](https://other.example/ok) [ext bad](https://other.example/gone)\n" +
+		"![logo](/logo.png)\n")},
+	"doc/index.md": {Data: []byte("# Docs\n\n[intro](intro) [self](http://goplus.org/doc/#docs)\n")},
+	"doc/intro.md": {Data: []byte("# Intro\n\n## Setup\n\n[up](../#nowhere)\n")},
+	"old.md":       {Data: []byte("---\nredirect: /doc/intro\n---\n")},
+	"logo.png":     {Data: []byte("\x89PNG")},
+}
+
+type fakeTransport map[string]int
+
+func (t fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	code, ok := t[req.URL.String()]
+	if !ok {
+		code = http.StatusNotFound
+	}
+	return &http.Response{
+		StatusCode: code,
+		Status:     fmt.Sprintf("%d %s", code, http.StatusText(code)),
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}, nil
+}
+
+func TestCheck(t *testing.T) {
+	site := web.NewSite(testSite)
+	pages, err := site.PageURLs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(pages, " "), "/ /doc/ /doc/intro"; got != want {
+		t.Fatalf("PageURLs() = %s, want %s", got, want)
+	}
+
+	c := &Checker{Handler: site, Hosts: []string{"goplus.org"}}
+	want := `/: /doc/intro#nope: no element with id "nope" in /doc/intro
+/: /doc/missing: 404 Not Found
+/doc/intro: ../#nowhere: no element with id "nowhere" in /
+`
+	if got := format(c.Check(pages)); got != want {
+		t.Errorf("Check without client:\n%s\nwant:\n%s", got, want)
+	}
+
+	c = &Checker{
+		Handler: site,
+		Hosts:   []string{"goplus.org"},
+		Client:  &http.Client{Transport: fakeTransport{"https://other.example/ok": 200}},
+	}
+	want = `/: /doc/intro#nope: no element with id "nope" in /doc/intro
+/: /doc/missing: 404 Not Found
+/: https://other.example/gone: 404 Not Found
+/doc/intro: ../#nowhere: no element with id "nowhere" in /
+`
+	if got := format(c.Check(pages)); got != want {
+		t.Errorf("Check with client:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func format(list []*Broken) string {
+	var b strings.Builder
+	for _, x := range list {
+		b.WriteString(x.String() + "\n")
+	}
+	return b.String()
+}
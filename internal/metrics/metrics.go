@@ -0,0 +1,275 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package metrics implements HTTP middleware that writes structured
+// access logs and collects request metrics for a web.Site,
+// exposing them in the Prometheus text format.
+//
+// The middleware attaches a web.Trace to each request, so that
+// a Site serving the request records the route class
+// (page, dir, text, file, or error) and the time spent parsing
+// and executing templates and converting Markdown.
+// Requests not served by a Site are classified as “other”.
+//
+// The metrics exported are:
+//
+//	goporg_http_requests_total{class, code}        counter
+//	goporg_http_request_duration_seconds{class}    histogram
+//	goporg_render_duration_seconds{phase}          histogram (phase = parse, execute, markdown)
+//	goporg_render_abandoned_total                  counter
+//
+// The abandoned counter counts template executions that ran past the
+// Site's render time limit and were left running in the background.
+package metrics
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/goplus/website/internal/web"
+)
+
+// buckets are the histogram bucket upper bounds, in seconds.
+var buckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// A histogram is a Prometheus-style cumulative histogram.
+type histogram struct {
+	counts []uint64 // counts[i] is number of observations <= buckets[i]
+	count  uint64
+	sum    float64
+}
+
+func (h *histogram) observe(d time.Duration) {
+	if h.counts == nil {
+		h.counts = make([]uint64, len(buckets))
+	}
+	v := d.Seconds()
+	for i, b := range buckets {
+		if v <= b {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+// A Metrics collects request metrics and writes access logs.
+// It is an http.Handler serving the metrics in the Prometheus text format.
+type Metrics struct {
+	log io.Writer // access log destination; nil for no logging
+
+	mu        sync.Mutex
+	requests  map[[2]string]uint64 // [class, code] -> count
+	latency   map[string]*histogram
+	render    map[string]*histogram
+	abandoned uint64 // template executions abandoned at the time limit
+	logMu     sync.Mutex
+}
+
+// New returns a new Metrics.
+// If log is not nil, the middleware returned by Wrap writes
+// one JSON object per request to log.
+func New(log io.Writer) *Metrics {
+	return &Metrics{
+		log:      log,
+		requests: make(map[[2]string]uint64),
+		latency:  make(map[string]*histogram),
+		render:   make(map[string]*histogram),
+	}
+}
+
+// An entry is a single access log entry.
+type entry struct {
+	Time       time.Time `json:"time"`
+	Method     string    `json:"method"`
+	Host       string    `json:"host"`
+	Path       string    `json:"path"`
+	Query      string    `json:"query,omitempty"`
+	Status     int       `json:"status"`
+	Bytes      int64     `json:"bytes"`
+	Class      string    `json:"class"`
+	Duration   float64   `json:"duration_ms"`
+	Parse      float64   `json:"parse_ms,omitempty"`
+	Execute    float64   `json:"execute_ms,omitempty"`
+	Markdown   float64   `json:"markdown_ms,omitempty"`
+	Abandoned  int       `json:"abandoned,omitempty"`
+	RemoteAddr string    `json:"remote_addr"`
+	Referer    string    `json:"referer,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// Wrap returns an http.Handler that passes requests to h,
+// recording metrics and writing access log entries for each one.
+func (m *Metrics) Wrap(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		t := new(web.Trace)
+		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(rw, web.WithTrace(r, t))
+		d := time.Since(start)
+
+		class := t.Class
+		if class == "" {
+			class = "other"
+		}
+		m.record(class, rw.status, d, t)
+
+		if m.log != nil {
+			host, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				host = r.RemoteAddr
+			}
+			js, err := json.Marshal(&entry{
+				Time:       start.UTC(),
+				Method:     r.Method,
+				Host:       r.Host,
+				Path:       r.URL.Path,
+				Query:      r.URL.RawQuery,
+				Status:     rw.status,
+				Bytes:      rw.bytes,
+				Class:      class,
+				Duration:   ms(d),
+				Parse:      ms(t.Parse),
+				Execute:    ms(t.Execute),
+				Markdown:   ms(t.Markdown),
+				Abandoned:  t.Abandoned,
+				RemoteAddr: host,
+				Referer:    r.Referer(),
+				UserAgent:  r.UserAgent(),
+			})
+			if err == nil {
+				m.logMu.Lock()
+				m.log.Write(append(js, '\n'))
+				m.logMu.Unlock()
+			}
+		}
+	})
+}
+
+// record records a single request in the metrics.
+func (m *Metrics) record(class string, status int, d time.Duration, t *web.Trace) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[[2]string{class, fmt.Sprint(status)}]++
+	h := m.latency[class]
+	if h == nil {
+		h = new(histogram)
+		m.latency[class] = h
+	}
+	h.observe(d)
+	m.abandoned += uint64(t.Abandoned)
+	for _, p := range []struct {
+		phase string
+		d     time.Duration
+	}{
+		{"parse", t.Parse},
+		{"execute", t.Execute},
+		{"markdown", t.Markdown},
+	} {
+		if p.d == 0 {
+			continue
+		}
+		h := m.render[p.phase]
+		if h == nil {
+			h = new(histogram)
+			m.render[p.phase] = h
+		}
+		h.observe(p.d)
+	}
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintf(bw, "# HELP goporg_http_requests_total Total HTTP requests by route class and status code.\n")
+	fmt.Fprintf(bw, "# TYPE goporg_http_requests_total counter\n")
+	var keys [][2]string
+	for k := range m.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	for _, k := range keys {
+		fmt.Fprintf(bw, "goporg_http_requests_total{class=%q,code=%q} %d\n", k[0], k[1], m.requests[k])
+	}
+
+	fmt.Fprintf(bw, "# HELP goporg_render_abandoned_total Template executions abandoned at the render time limit.\n")
+	fmt.Fprintf(bw, "# TYPE goporg_render_abandoned_total counter\n")
+	fmt.Fprintf(bw, "goporg_render_abandoned_total %d\n", m.abandoned)
+
+	writeHistograms(bw, "goporg_http_request_duration_seconds", "HTTP request latency by route class.", "class", m.latency)
+	writeHistograms(bw, "goporg_render_duration_seconds", "Page rendering time by phase.", "phase", m.render)
+}
+
+// writeHistograms writes the histograms in hs, labeled by label.
+func writeHistograms(w io.Writer, name, help, label string, hs map[string]*histogram) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s histogram\n", name)
+	var keys []string
+	for k := range hs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		h := hs[k]
+		for i, b := range buckets {
+			fmt.Fprintf(w, "%s_bucket{%s=%q,le=\"%g\"} %d\n", name, label, k, b, h.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket{%s=%q,le=\"+Inf\"} %d\n", name, label, k, h.count)
+		fmt.Fprintf(w, "%s_sum{%s=%q} %g\n", name, label, k, h.sum)
+		fmt.Fprintf(w, "%s_count{%s=%q} %d\n", name, label, k, h.count)
+	}
+}
+
+// A responseWriter is an http.ResponseWriter
+// that records the status code and number of bytes written.
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status = code
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher if the underlying ResponseWriter does.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
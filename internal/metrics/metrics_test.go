@@ -0,0 +1,68 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/goplus/website/internal/web"
+)
+
+func TestMetrics(t *testing.T) {
+	site := web.NewSite(fstest.MapFS{
+		"site.tmpl":     {Data: []byte(`{{.Content}}`)},
+		"error.tmpl":    {Data: []byte(`{{define "layout"}}{{.error}}{{end}}`)},
+		"dir.tmpl":      {Data: []byte(`{{define "layout"}}dir{{end}}`)},
+		"doc/page.md":   {Data: []byte("**bold**")},
+		"doc/style.css": {Data: []byte("body {}")},
+	})
+	var log bytes.Buffer
+	m := New(&log)
+	h := m.Wrap(site)
+
+	for i, path := range []string{"/doc/page", "/doc/page", "/doc/", "/doc/style.css", "/missing"} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		// The second /doc/page is served from the site's output cache, without rendering.
+		if i == 0 && !strings.Contains(w.Header().Get("Server-Timing"), "markdown;dur=") {
+			t.Errorf("GET %s: Server-Timing = %q, want markdown timing", path, w.Header().Get("Server-Timing"))
+		}
+	}
+
+	var classes []string
+	for _, line := range strings.Split(strings.TrimSpace(log.String()), "\n") {
+		var e entry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("bad log line %q: %v", line, err)
+		}
+		classes = append(classes, e.Class+" "+e.Path+" "+http.StatusText(e.Status))
+	}
+	want := "page /doc/page OK,page /doc/page OK,dir /doc/ OK,file /doc/style.css OK,error /missing Not Found"
+	if got := strings.Join(classes, ","); got != want {
+		t.Errorf("access log classes:\n%s\nwant:\n%s", got, want)
+	}
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	out := w.Body.String()
+	for _, s := range []string{
+		`goporg_http_requests_total{class="page",code="200"} 2`,
+		`goporg_http_requests_total{class="error",code="404"} 1`,
+		`goporg_http_request_duration_seconds_count{class="dir"} 1`,
+		`goporg_http_request_duration_seconds_bucket{class="file",le="+Inf"} 1`,
+		`goporg_render_duration_seconds_count{phase="markdown"} 1`,
+		`goporg_render_abandoned_total 0`,
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("metrics missing %s\n%s", s, out)
+		}
+	}
+}
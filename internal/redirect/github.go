@@ -0,0 +1,95 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package redirect
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// githubOrg is the GitHub organization holding the Go+ repositories.
+const githubOrg = "https://github.com/goplus/"
+
+// defaultRepo is the repository used by the shortcuts
+// when the URL does not name one.
+const defaultRepo = "gop"
+
+// A githubShortcut describes one kind of shortcut URL
+// that redirects into the GitHub repositories of githubOrg.
+type githubShortcut struct {
+	prefix string         // URL prefix, such as "/issue/"
+	list   string         // path of the list page within a repo, such as "issues"
+	item   string         // path of a single item within a repo, such as "issues/"
+	id     *regexp.Regexp // valid item IDs
+}
+
+var (
+	validRepo  = regexp.MustCompile(`^[A-Za-z0-9\-._]+$`)
+	validIssue = regexp.MustCompile(`^([0-9]+|new)$`)
+	validSHA   = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+	validTag   = regexp.MustCompile(`^v[0-9][0-9A-Za-z\-.+]*$`)
+)
+
+var githubShortcuts = []githubShortcut{
+	{"/issue/", "issues", "issues/", validIssue},
+	{"/issues/", "issues", "issues/", validIssue},
+	{"/pr/", "pulls", "pull/", regexp.MustCompile(`^[0-9]+$`)},
+	{"/commit/", "commits", "commit/", validSHA},
+	{"/release/", "releases", "releases/tag/", validTag},
+}
+
+// registerGitHub registers the handlers for the GitHub shortcuts.
+// Like the list pages, the new issue pages redirect permanently;
+// the other shortcuts redirect temporarily.
+func registerGitHub(mux *http.ServeMux) {
+	for _, s := range githubShortcuts {
+		mux.Handle(strings.TrimSuffix(s.prefix, "/"), Handler(githubOrg+defaultRepo+"/"+s.list))
+		mux.Handle(s.prefix, s)
+	}
+	mux.Handle("/issue/new", Handler(githubOrg+defaultRepo+"/issues/new"))
+	mux.Handle("/issues/new", Handler(githubOrg+defaultRepo+"/issues/new"))
+}
+
+// ServeHTTP redirects requests for the shortcut URLs:
+//
+//	prefix/id       - item id in defaultRepo
+//	prefix/repo     - list page of repo
+//	prefix/repo/id  - item id in repo
+//
+// For example, with prefix /issue/, /issue/123 redirects to
+// https://github.com/goplus/gop/issues/123, and /issue/gox/4
+// redirects to https://github.com/goplus/gox/issues/4.
+//
+// A single element that is a valid id is taken as an id, not a repo.
+// For /commit/, ids are commit hashes of at least 7 hex digits,
+// so /commit/abcdef12 names a commit in defaultRepo, even if a repo
+// named abcdef12 exists, but /commit/cafe names the repo cafe.
+func (s githubShortcut) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if p := r.URL.Path; p == s.prefix {
+		// redirect /prefix/ to /prefix
+		http.Redirect(w, r, p[:len(p)-1], http.StatusFound)
+		return
+	}
+	elems := strings.Split(strings.TrimSuffix(r.URL.Path[len(s.prefix):], "/"), "/")
+	target := ""
+	switch len(elems) {
+	case 1:
+		if s.id.MatchString(elems[0]) {
+			target = githubOrg + defaultRepo + "/" + s.item + elems[0]
+		} else if validRepo.MatchString(elems[0]) {
+			target = githubOrg + elems[0] + "/" + s.list
+		}
+	case 2:
+		if validRepo.MatchString(elems[0]) && s.id.MatchString(elems[1]) {
+			target = githubOrg + elems[0] + "/" + s.item + elems[1]
+		}
+	}
+	if target == "" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	http.Redirect(w, r, target, http.StatusFound)
+}
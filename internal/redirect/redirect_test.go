@@ -21,62 +21,37 @@ func errorResult(status int) redirectResult {
 
 func TestRedirects(t *testing.T) {
 	var tests = map[string]redirectResult{
-		"/build":       {301, "https://build.golang.org"},
-		"/ref":         {301, "/doc/#references"},
-		"/doc/mem":     {301, "/ref/mem"},
-		"/doc/spec":    {301, "/ref/spec"},
-		"/tour":        {301, "https://tour.golang.org"},
-		"/foo":         errorResult(404),
-		"/blog":        {301, "https://go.dev/blog"},
-		"/blog/":       {302, "/blog"},
-		"/blog/go1.16": {302, "https://go.dev/blog/go1.16"},
-
-		"/pkg/asn1":           {301, "/pkg/encoding/asn1/"},
-		"/pkg/template/parse": {301, "/pkg/text/template/parse/"},
-		"/pkg/C":              {301, "/pkg/C/"},
-		"/pkg/C/":             {301, "/cmd/cgo/"},
-		"/pkg/C/foo":          {301, "/cmd/cgo/"}, // historical
+		"/foo": errorResult(404),
 
 		"/src/pkg/foo": {301, "/src/foo"},
 
-		"/cmd/gofix": {301, "/cmd/fix/"},
-
-		// git commits (/change)
-		// TODO: mercurial tags and LoadChangeMap.
-		"/change":   {301, "https://go.googlesource.com/go"},
-		"/change/a": {302, "https://go.googlesource.com/go/+/a"},
-
-		"/issue":                    {301, "https://github.com/golang/go/issues"},
-		"/issue?":                   {301, "https://github.com/golang/go/issues"},
-		"/issue/1":                  {302, "https://github.com/golang/go/issues/1"},
-		"/issue/new":                {301, "https://github.com/golang/go/issues/new"},
-		"/issue/new?a=b&c=d%20&e=f": {301, "https://github.com/golang/go/issues/new?a=b&c=d%20&e=f"},
-		"/issues":                   {301, "https://github.com/golang/go/issues"},
-		"/issues/1":                 {302, "https://github.com/golang/go/issues/1"},
-		"/issues/new":               {301, "https://github.com/golang/go/issues/new"},
-		"/issues/1/2/3":             errorResult(404),
-
-		"/wiki/foo":  {302, "https://github.com/golang/go/wiki/foo"},
-		"/wiki/foo/": {302, "https://github.com/golang/go/wiki/foo/"},
-
-		"/design":              {301, "https://go.googlesource.com/proposal/+/master/design"},
-		"/design/":             {302, "/design"},
-		"/design/123-foo":      {302, "https://go.googlesource.com/proposal/+/master/design/123-foo.md"},
-		"/design/text/123-foo": {302, "https://go.googlesource.com/proposal/+/master/design/text/123-foo.md"},
-
-		"/cl/1":          {302, "https://go-review.googlesource.com/1"},
-		"/cl/1/":         {302, "https://go-review.googlesource.com/1"},
-		"/cl/267120043":  {302, "https://codereview.appspot.com/267120043"},
-		"/cl/267120043/": {302, "https://codereview.appspot.com/267120043"},
-
-		// Verify that we're using the Rietveld CL table:
-		"/cl/152046": {302, "https://codereview.appspot.com/152046"},
-		"/cl/152047": {302, "https://go-review.googlesource.com/152047"},
-		"/cl/152048": {302, "https://codereview.appspot.com/152048"},
-
-		// And verify we're using the "bigEnoughAssumeRietveld" value:
-		"/cl/3999999": {302, "https://go-review.googlesource.com/3999999"},
-		"/cl/4000000": {302, "https://codereview.appspot.com/4000000"},
+		"/issue":              {301, "https://github.com/goplus/gop/issues"},
+		"/issue?q=is:open":    {301, "https://github.com/goplus/gop/issues?q=is:open"},
+		"/issue/":             {302, "/issue"},
+		"/issue/1":            {302, "https://github.com/goplus/gop/issues/1"},
+		"/issue/1/":           {302, "https://github.com/goplus/gop/issues/1"},
+		"/issue/new":          {301, "https://github.com/goplus/gop/issues/new"},
+		"/issues/new":         {301, "https://github.com/goplus/gop/issues/new"},
+		"/issue/gox":          {302, "https://github.com/goplus/gox/issues"},
+		"/issue/gox/12":       {302, "https://github.com/goplus/gox/issues/12"},
+		"/issue/gox/new":      {302, "https://github.com/goplus/gox/issues/new"},
+		"/issue/gox/x":        errorResult(404),
+		"/issues/1/2/3":       errorResult(404),
+		"/issues/1":           {302, "https://github.com/goplus/gop/issues/1"},
+		"/pr":                 {301, "https://github.com/goplus/gop/pulls"},
+		"/pr/42":              {302, "https://github.com/goplus/gop/pull/42"},
+		"/pr/gox/7":           {302, "https://github.com/goplus/gox/pull/7"},
+		"/commit/1a2b3c4":     {302, "https://github.com/goplus/gop/commit/1a2b3c4"},
+		"/commit/gox/abcd12":  errorResult(404),
+		"/commit/gox/abcd123": {302, "https://github.com/goplus/gox/commit/abcd123"},
+		"/commit/cafe":        {302, "https://github.com/goplus/cafe/commits"},
+		"/commit/gox/xyz":     errorResult(404),
+		"/release":            {301, "https://github.com/goplus/gop/releases"},
+		"/release/v1.0.0":     {302, "https://github.com/goplus/gop/releases/tag/v1.0.0"},
+		"/release/gox":        {302, "https://github.com/goplus/gox/releases"},
+		"/release/gox/v1.8":   {302, "https://github.com/goplus/gox/releases/tag/v1.8"},
+
+		"/design/": errorResult(404),
 	}
 
 	mux := http.NewServeMux()
@@ -1,3269 +0,0 @@
-// Copyright 2018 The Go Authors. All rights reserved.
-// Use of this source code is governed by a BSD-style
-// license that can be found in the LICENSE file.
-
-package redirect
-
-// bigEnoughAssumeRietveld is the value where CLs equal or great are
-// assumed to be on Rietveld. By including this threshold we shrink
-// the size of the table below. When Go amasses more CLs, we'll need
-// to bump this number, regenerate the list below, and handle subrepos
-// (see below).
-const bigEnoughAssumeRietveld = 4000000
-
-// isRietveldCL reports whether cl was a Rietveld CL number.
-func isRietveldCL(cl int) bool {
-	return cl >= bigEnoughAssumeRietveld || lowRietveldCL[cl]
-}
-
-// lowRietveldCLs are the old CL numbers assigned by Rietveld code
-// review system as used by Go prior to Gerrit which are less than
-// bigEnoughAssumeRietveld.
-//
-// This list of numbers is registered with the /cl/NNNN redirect
-// handler to disambiguate which code review system a particular
-// number corresponds to. In some rare cases there may be duplicates,
-// in which case we might render an HTML choice for the user.
-//
-// To re-generate this list, run:
-//
-// $ cd $GOROOT
-// $ git log 7d7c6a9..94151eb | grep "^    https://golang.org/cl/" | perl -ne 's,^\s+https://golang.org/cl/(\d+).*$,$1,; chomp; print "$_: true,\n" if $_ < 4000000' | sort -n | uniq
-//
-// Note that we ignore the x/* repos because we didn't start using
-// "subrepos" until the Rietveld CLs numbers were already 4,000,000+,
-// above bigEnoughAssumeRietveld.
-var lowRietveldCL = map[int]bool{
-	152046:  true,
-	152048:  true,
-	152049:  true,
-	152050:  true,
-	152051:  true,
-	152052:  true,
-	152055:  true,
-	152056:  true,
-	152057:  true,
-	152072:  true,
-	152073:  true,
-	152075:  true,
-	152076:  true,
-	152077:  true,
-	152078:  true,
-	152079:  true,
-	152080:  true,
-	152082:  true,
-	152084:  true,
-	152085:  true,
-	152086:  true,
-	152088:  true,
-	152089:  true,
-	152091:  true,
-	152098:  true,
-	152101:  true,
-	152102:  true,
-	152105:  true,
-	152106:  true,
-	152107:  true,
-	152108:  true,
-	152109:  true,
-	152110:  true,
-	152114:  true,
-	152117:  true,
-	152118:  true,
-	152120:  true,
-	152123:  true,
-	152124:  true,
-	152128:  true,
-	152130:  true,
-	152131:  true,
-	152138:  true,
-	152141:  true,
-	152142:  true,
-	153048:  true,
-	153049:  true,
-	153050:  true,
-	153051:  true,
-	153055:  true,
-	153056:  true,
-	153057:  true,
-	154043:  true,
-	154044:  true,
-	154045:  true,
-	154049:  true,
-	154055:  true,
-	154057:  true,
-	154058:  true,
-	154059:  true,
-	154061:  true,
-	154064:  true,
-	154065:  true,
-	154067:  true,
-	154068:  true,
-	154069:  true,
-	154071:  true,
-	154072:  true,
-	154073:  true,
-	154076:  true,
-	154079:  true,
-	154096:  true,
-	154097:  true,
-	154099:  true,
-	154100:  true,
-	154101:  true,
-	154102:  true,
-	154108:  true,
-	154118:  true,
-	154121:  true,
-	154122:  true,
-	154123:  true,
-	154125:  true,
-	154126:  true,
-	154128:  true,
-	154136:  true,
-	154138:  true,
-	154139:  true,
-	154140:  true,
-	154141:  true,
-	154142:  true,
-	154143:  true,
-	154144:  true,
-	154145:  true,
-	154146:  true,
-	154152:  true,
-	154153:  true,
-	154156:  true,
-	154159:  true,
-	154161:  true,
-	154166:  true,
-	154167:  true,
-	154169:  true,
-	154171:  true,
-	154172:  true,
-	154173:  true,
-	154174:  true,
-	154175:  true,
-	154176:  true,
-	154177:  true,
-	154178:  true,
-	154179:  true,
-	154180:  true,
-	155041:  true,
-	155042:  true,
-	155045:  true,
-	155047:  true,
-	155048:  true,
-	155049:  true,
-	155050:  true,
-	155054:  true,
-	155055:  true,
-	155056:  true,
-	155057:  true,
-	155058:  true,
-	155059:  true,
-	155061:  true,
-	155062:  true,
-	155063:  true,
-	155065:  true,
-	155067:  true,
-	155069:  true,
-	155072:  true,
-	155074:  true,
-	155075:  true,
-	155077:  true,
-	155078:  true,
-	155079:  true,
-	156041:  true,
-	156044:  true,
-	156045:  true,
-	156046:  true,
-	156047:  true,
-	156051:  true,
-	156052:  true,
-	156054:  true,
-	156055:  true,
-	156056:  true,
-	156058:  true,
-	156059:  true,
-	156060:  true,
-	156061:  true,
-	156062:  true,
-	156063:  true,
-	156066:  true,
-	156067:  true,
-	156070:  true,
-	156071:  true,
-	156073:  true,
-	156075:  true,
-	156077:  true,
-	156079:  true,
-	156080:  true,
-	156081:  true,
-	156083:  true,
-	156084:  true,
-	156085:  true,
-	156086:  true,
-	156089:  true,
-	156091:  true,
-	156092:  true,
-	156093:  true,
-	156094:  true,
-	156097:  true,
-	156099:  true,
-	156100:  true,
-	156102:  true,
-	156103:  true,
-	156104:  true,
-	156106:  true,
-	156107:  true,
-	156108:  true,
-	156109:  true,
-	156110:  true,
-	156113:  true,
-	156115:  true,
-	156116:  true,
-	157041:  true,
-	157042:  true,
-	157043:  true,
-	157044:  true,
-	157046:  true,
-	157053:  true,
-	157055:  true,
-	157056:  true,
-	157058:  true,
-	157060:  true,
-	157061:  true,
-	157062:  true,
-	157065:  true,
-	157066:  true,
-	157067:  true,
-	157068:  true,
-	157069:  true,
-	157071:  true,
-	157072:  true,
-	157073:  true,
-	157074:  true,
-	157075:  true,
-	157076:  true,
-	157077:  true,
-	157082:  true,
-	157084:  true,
-	157085:  true,
-	157087:  true,
-	157088:  true,
-	157091:  true,
-	157095:  true,
-	157096:  true,
-	157099:  true,
-	157100:  true,
-	157101:  true,
-	157102:  true,
-	157103:  true,
-	157104:  true,
-	157106:  true,
-	157110:  true,
-	157111:  true,
-	157112:  true,
-	157114:  true,
-	157116:  true,
-	157119:  true,
-	157140:  true,
-	157142:  true,
-	157143:  true,
-	157144:  true,
-	157146:  true,
-	157147:  true,
-	157149:  true,
-	157151:  true,
-	157152:  true,
-	157153:  true,
-	157154:  true,
-	157156:  true,
-	157157:  true,
-	157158:  true,
-	157159:  true,
-	157160:  true,
-	157162:  true,
-	157166:  true,
-	157167:  true,
-	157168:  true,
-	157170:  true,
-	158041:  true,
-	159044:  true,
-	159049:  true,
-	159050:  true,
-	159051:  true,
-	160043:  true,
-	160044:  true,
-	160045:  true,
-	160046:  true,
-	160047:  true,
-	160054:  true,
-	160056:  true,
-	160057:  true,
-	160059:  true,
-	160060:  true,
-	160061:  true,
-	160064:  true,
-	160065:  true,
-	160069:  true,
-	160070:  true,
-	161049:  true,
-	161050:  true,
-	161056:  true,
-	161058:  true,
-	161060:  true,
-	161061:  true,
-	161069:  true,
-	161070:  true,
-	161073:  true,
-	161075:  true,
-	162041:  true,
-	162044:  true,
-	162046:  true,
-	162053:  true,
-	162054:  true,
-	162055:  true,
-	162056:  true,
-	162057:  true,
-	162058:  true,
-	162059:  true,
-	162061:  true,
-	162062:  true,
-	163042:  true,
-	163044:  true,
-	163049:  true,
-	163050:  true,
-	163051:  true,
-	163052:  true,
-	163053:  true,
-	163055:  true,
-	163058:  true,
-	163061:  true,
-	163062:  true,
-	163064:  true,
-	163067:  true,
-	163068:  true,
-	163069:  true,
-	163070:  true,
-	163071:  true,
-	163072:  true,
-	163082:  true,
-	163083:  true,
-	163085:  true,
-	163088:  true,
-	163091:  true,
-	163092:  true,
-	163097:  true,
-	163098:  true,
-	164043:  true,
-	164047:  true,
-	164049:  true,
-	164052:  true,
-	164053:  true,
-	164056:  true,
-	164059:  true,
-	164060:  true,
-	164062:  true,
-	164068:  true,
-	164069:  true,
-	164071:  true,
-	164073:  true,
-	164074:  true,
-	164075:  true,
-	164078:  true,
-	164079:  true,
-	164081:  true,
-	164082:  true,
-	164083:  true,
-	164085:  true,
-	164086:  true,
-	164088:  true,
-	164090:  true,
-	164091:  true,
-	164092:  true,
-	164093:  true,
-	164094:  true,
-	164095:  true,
-	165042:  true,
-	165044:  true,
-	165045:  true,
-	165048:  true,
-	165049:  true,
-	165050:  true,
-	165051:  true,
-	165055:  true,
-	165057:  true,
-	165058:  true,
-	165059:  true,
-	165061:  true,
-	165062:  true,
-	165063:  true,
-	165064:  true,
-	165065:  true,
-	165068:  true,
-	165070:  true,
-	165076:  true,
-	165078:  true,
-	165080:  true,
-	165083:  true,
-	165086:  true,
-	165097:  true,
-	165100:  true,
-	165101:  true,
-	166041:  true,
-	166043:  true,
-	166044:  true,
-	166047:  true,
-	166049:  true,
-	166052:  true,
-	166053:  true,
-	166055:  true,
-	166058:  true,
-	166059:  true,
-	166060:  true,
-	166064:  true,
-	166066:  true,
-	166067:  true,
-	166068:  true,
-	166070:  true,
-	166071:  true,
-	166072:  true,
-	166073:  true,
-	166074:  true,
-	166076:  true,
-	166077:  true,
-	166078:  true,
-	166080:  true,
-	167043:  true,
-	167044:  true,
-	167047:  true,
-	167050:  true,
-	167055:  true,
-	167057:  true,
-	167058:  true,
-	168041:  true,
-	168045:  true,
-	170042:  true,
-	170043:  true,
-	170044:  true,
-	170046:  true,
-	170047:  true,
-	170048:  true,
-	170049:  true,
-	171044:  true,
-	171046:  true,
-	171047:  true,
-	171048:  true,
-	171051:  true,
-	172041:  true,
-	172042:  true,
-	172043:  true,
-	172045:  true,
-	172049:  true,
-	173041:  true,
-	173044:  true,
-	173045:  true,
-	174042:  true,
-	174047:  true,
-	174048:  true,
-	174050:  true,
-	174051:  true,
-	174052:  true,
-	174053:  true,
-	174063:  true,
-	174064:  true,
-	174072:  true,
-	174076:  true,
-	174077:  true,
-	174078:  true,
-	174082:  true,
-	174083:  true,
-	174087:  true,
-	175045:  true,
-	175046:  true,
-	175047:  true,
-	175048:  true,
-	176056:  true,
-	176057:  true,
-	176058:  true,
-	176061:  true,
-	176062:  true,
-	176063:  true,
-	176064:  true,
-	176066:  true,
-	176067:  true,
-	176070:  true,
-	176071:  true,
-	176076:  true,
-	178043:  true,
-	178044:  true,
-	178046:  true,
-	178048:  true,
-	179047:  true,
-	179055:  true,
-	179061:  true,
-	179062:  true,
-	179063:  true,
-	179067:  true,
-	179069:  true,
-	179070:  true,
-	179072:  true,
-	179079:  true,
-	179088:  true,
-	179095:  true,
-	179096:  true,
-	179097:  true,
-	179099:  true,
-	179105:  true,
-	179106:  true,
-	179108:  true,
-	179118:  true,
-	179120:  true,
-	179125:  true,
-	179126:  true,
-	179128:  true,
-	179129:  true,
-	179130:  true,
-	180044:  true,
-	180045:  true,
-	180046:  true,
-	180047:  true,
-	180048:  true,
-	180049:  true,
-	180050:  true,
-	180052:  true,
-	180053:  true,
-	180054:  true,
-	180055:  true,
-	180056:  true,
-	180057:  true,
-	180059:  true,
-	180061:  true,
-	180064:  true,
-	180065:  true,
-	180068:  true,
-	180069:  true,
-	180070:  true,
-	180074:  true,
-	180075:  true,
-	180081:  true,
-	180082:  true,
-	180085:  true,
-	180092:  true,
-	180099:  true,
-	180105:  true,
-	180108:  true,
-	180112:  true,
-	180118:  true,
-	181041:  true,
-	181043:  true,
-	181044:  true,
-	181045:  true,
-	181049:  true,
-	181050:  true,
-	181055:  true,
-	181057:  true,
-	181058:  true,
-	181059:  true,
-	181063:  true,
-	181071:  true,
-	181073:  true,
-	181075:  true,
-	181077:  true,
-	181080:  true,
-	181083:  true,
-	181084:  true,
-	181085:  true,
-	181086:  true,
-	181087:  true,
-	181089:  true,
-	181097:  true,
-	181099:  true,
-	181102:  true,
-	181111:  true,
-	181130:  true,
-	181135:  true,
-	181137:  true,
-	181138:  true,
-	181139:  true,
-	181151:  true,
-	181152:  true,
-	181153:  true,
-	181155:  true,
-	181156:  true,
-	181157:  true,
-	181158:  true,
-	181160:  true,
-	181161:  true,
-	181163:  true,
-	181164:  true,
-	181171:  true,
-	181179:  true,
-	181183:  true,
-	181184:  true,
-	181186:  true,
-	182041:  true,
-	182043:  true,
-	182044:  true,
-	183042:  true,
-	183043:  true,
-	183044:  true,
-	183047:  true,
-	183049:  true,
-	183065:  true,
-	183066:  true,
-	183073:  true,
-	183074:  true,
-	183075:  true,
-	183083:  true,
-	183084:  true,
-	183087:  true,
-	183088:  true,
-	183090:  true,
-	183095:  true,
-	183104:  true,
-	183107:  true,
-	183109:  true,
-	183111:  true,
-	183112:  true,
-	183113:  true,
-	183116:  true,
-	183123:  true,
-	183124:  true,
-	183125:  true,
-	183126:  true,
-	183132:  true,
-	183133:  true,
-	183135:  true,
-	183136:  true,
-	183137:  true,
-	183138:  true,
-	183139:  true,
-	183140:  true,
-	183141:  true,
-	183142:  true,
-	183153:  true,
-	183155:  true,
-	183156:  true,
-	183157:  true,
-	183160:  true,
-	184043:  true,
-	184055:  true,
-	184058:  true,
-	184059:  true,
-	184068:  true,
-	184069:  true,
-	184079:  true,
-	184080:  true,
-	184081:  true,
-	185043:  true,
-	185045:  true,
-	186042:  true,
-	186043:  true,
-	186073:  true,
-	186076:  true,
-	186077:  true,
-	186078:  true,
-	186079:  true,
-	186081:  true,
-	186095:  true,
-	186108:  true,
-	186113:  true,
-	186115:  true,
-	186116:  true,
-	186118:  true,
-	186119:  true,
-	186132:  true,
-	186137:  true,
-	186138:  true,
-	186139:  true,
-	186143:  true,
-	186144:  true,
-	186145:  true,
-	186146:  true,
-	186147:  true,
-	186148:  true,
-	186159:  true,
-	186160:  true,
-	186161:  true,
-	186165:  true,
-	186169:  true,
-	186173:  true,
-	186180:  true,
-	186210:  true,
-	186211:  true,
-	186212:  true,
-	186213:  true,
-	186214:  true,
-	186215:  true,
-	186216:  true,
-	186228:  true,
-	186229:  true,
-	186230:  true,
-	186232:  true,
-	186234:  true,
-	186255:  true,
-	186263:  true,
-	186276:  true,
-	186279:  true,
-	186282:  true,
-	186283:  true,
-	188043:  true,
-	189042:  true,
-	189057:  true,
-	189059:  true,
-	189062:  true,
-	189078:  true,
-	189080:  true,
-	189083:  true,
-	189088:  true,
-	189093:  true,
-	189095:  true,
-	189096:  true,
-	189098:  true,
-	189100:  true,
-	190041:  true,
-	190042:  true,
-	190043:  true,
-	190044:  true,
-	190059:  true,
-	190062:  true,
-	190068:  true,
-	190074:  true,
-	190076:  true,
-	190077:  true,
-	190079:  true,
-	190085:  true,
-	190088:  true,
-	190103:  true,
-	190104:  true,
-	193055:  true,
-	193066:  true,
-	193067:  true,
-	193070:  true,
-	193075:  true,
-	193079:  true,
-	193080:  true,
-	193081:  true,
-	193091:  true,
-	193092:  true,
-	193095:  true,
-	193101:  true,
-	193104:  true,
-	194043:  true,
-	194045:  true,
-	194046:  true,
-	194050:  true,
-	194051:  true,
-	194052:  true,
-	194053:  true,
-	194064:  true,
-	194066:  true,
-	194069:  true,
-	194071:  true,
-	194072:  true,
-	194073:  true,
-	194074:  true,
-	194076:  true,
-	194077:  true,
-	194078:  true,
-	194082:  true,
-	194084:  true,
-	194085:  true,
-	194090:  true,
-	194091:  true,
-	194092:  true,
-	194094:  true,
-	194097:  true,
-	194098:  true,
-	194099:  true,
-	194100:  true,
-	194114:  true,
-	194116:  true,
-	194118:  true,
-	194119:  true,
-	194120:  true,
-	194121:  true,
-	194122:  true,
-	194126:  true,
-	194129:  true,
-	194131:  true,
-	194132:  true,
-	194133:  true,
-	194134:  true,
-	194146:  true,
-	194151:  true,
-	194156:  true,
-	194157:  true,
-	194159:  true,
-	194161:  true,
-	194165:  true,
-	195041:  true,
-	195044:  true,
-	195050:  true,
-	195051:  true,
-	195052:  true,
-	195068:  true,
-	195075:  true,
-	195076:  true,
-	195079:  true,
-	195080:  true,
-	195081:  true,
-	196042:  true,
-	196044:  true,
-	196050:  true,
-	196051:  true,
-	196055:  true,
-	196056:  true,
-	196061:  true,
-	196063:  true,
-	196065:  true,
-	196070:  true,
-	196071:  true,
-	196075:  true,
-	196077:  true,
-	196079:  true,
-	196087:  true,
-	196088:  true,
-	196090:  true,
-	196091:  true,
-	197041:  true,
-	197042:  true,
-	197043:  true,
-	197044:  true,
-	198044:  true,
-	198045:  true,
-	198046:  true,
-	198048:  true,
-	198049:  true,
-	198050:  true,
-	198053:  true,
-	198057:  true,
-	198058:  true,
-	198066:  true,
-	198071:  true,
-	198074:  true,
-	198081:  true,
-	198084:  true,
-	198085:  true,
-	198102:  true,
-	199042:  true,
-	199044:  true,
-	199045:  true,
-	199046:  true,
-	199047:  true,
-	199052:  true,
-	199054:  true,
-	199057:  true,
-	199066:  true,
-	199070:  true,
-	199082:  true,
-	199091:  true,
-	199094:  true,
-	199096:  true,
-	201041:  true,
-	201042:  true,
-	201043:  true,
-	201047:  true,
-	201048:  true,
-	201049:  true,
-	201058:  true,
-	201061:  true,
-	201064:  true,
-	201065:  true,
-	201068:  true,
-	202042:  true,
-	202043:  true,
-	202044:  true,
-	202051:  true,
-	202054:  true,
-	202055:  true,
-	203043:  true,
-	203050:  true,
-	203051:  true,
-	203053:  true,
-	203060:  true,
-	203062:  true,
-	204042:  true,
-	204044:  true,
-	204048:  true,
-	204052:  true,
-	204053:  true,
-	204061:  true,
-	204062:  true,
-	204064:  true,
-	204065:  true,
-	204067:  true,
-	204068:  true,
-	204069:  true,
-	205042:  true,
-	205044:  true,
-	206043:  true,
-	206044:  true,
-	206047:  true,
-	206050:  true,
-	206051:  true,
-	206052:  true,
-	206053:  true,
-	206054:  true,
-	206055:  true,
-	206058:  true,
-	206059:  true,
-	206060:  true,
-	206067:  true,
-	206069:  true,
-	206077:  true,
-	206078:  true,
-	206079:  true,
-	206084:  true,
-	206089:  true,
-	206101:  true,
-	206107:  true,
-	206109:  true,
-	207043:  true,
-	207044:  true,
-	207049:  true,
-	207050:  true,
-	207051:  true,
-	207052:  true,
-	207053:  true,
-	207054:  true,
-	207055:  true,
-	207061:  true,
-	207062:  true,
-	207069:  true,
-	207071:  true,
-	207085:  true,
-	207086:  true,
-	207087:  true,
-	207088:  true,
-	207095:  true,
-	207096:  true,
-	207102:  true,
-	207103:  true,
-	207106:  true,
-	207108:  true,
-	207110:  true,
-	207111:  true,
-	207112:  true,
-	209041:  true,
-	209042:  true,
-	209043:  true,
-	209044:  true,
-	210042:  true,
-	210043:  true,
-	210044:  true,
-	210047:  true,
-	211041:  true,
-	212041:  true,
-	212045:  true,
-	212046:  true,
-	212047:  true,
-	213041:  true,
-	213042:  true,
-	214042:  true,
-	214046:  true,
-	214049:  true,
-	214050:  true,
-	215042:  true,
-	215048:  true,
-	215050:  true,
-	216043:  true,
-	216046:  true,
-	216047:  true,
-	216052:  true,
-	216053:  true,
-	216054:  true,
-	216059:  true,
-	216068:  true,
-	217041:  true,
-	217044:  true,
-	217047:  true,
-	217048:  true,
-	217049:  true,
-	217056:  true,
-	217058:  true,
-	217059:  true,
-	217060:  true,
-	217061:  true,
-	217064:  true,
-	217066:  true,
-	217069:  true,
-	217071:  true,
-	217085:  true,
-	217086:  true,
-	217088:  true,
-	217093:  true,
-	217094:  true,
-	217108:  true,
-	217109:  true,
-	217111:  true,
-	217115:  true,
-	217116:  true,
-	218042:  true,
-	218044:  true,
-	218046:  true,
-	218050:  true,
-	218060:  true,
-	218061:  true,
-	218063:  true,
-	218064:  true,
-	218065:  true,
-	218070:  true,
-	218071:  true,
-	218072:  true,
-	218074:  true,
-	218076:  true,
-	222041:  true,
-	223041:  true,
-	223043:  true,
-	223044:  true,
-	223050:  true,
-	223052:  true,
-	223054:  true,
-	223058:  true,
-	223059:  true,
-	223061:  true,
-	223068:  true,
-	223069:  true,
-	223070:  true,
-	223071:  true,
-	223073:  true,
-	223075:  true,
-	223076:  true,
-	223083:  true,
-	223087:  true,
-	223094:  true,
-	223096:  true,
-	223101:  true,
-	223106:  true,
-	223108:  true,
-	224041:  true,
-	224042:  true,
-	224043:  true,
-	224045:  true,
-	224051:  true,
-	224053:  true,
-	224057:  true,
-	224060:  true,
-	224061:  true,
-	224062:  true,
-	224063:  true,
-	224068:  true,
-	224069:  true,
-	224081:  true,
-	224084:  true,
-	224087:  true,
-	224090:  true,
-	224096:  true,
-	224105:  true,
-	225042:  true,
-	227041:  true,
-	229045:  true,
-	229046:  true,
-	229048:  true,
-	229049:  true,
-	229050:  true,
-	231042:  true,
-	236041:  true,
-	237041:  true,
-	238041:  true,
-	238042:  true,
-	240041:  true,
-	240042:  true,
-	240043:  true,
-	241041:  true,
-	243041:  true,
-	244041:  true,
-	245041:  true,
-	247041:  true,
-	250041:  true,
-	252041:  true,
-	253041:  true,
-	253045:  true,
-	254043:  true,
-	255042:  true,
-	255043:  true,
-	257041:  true,
-	257042:  true,
-	258041:  true,
-	261041:  true,
-	264041:  true,
-	294042:  true,
-	296042:  true,
-	302042:  true,
-	310041:  true,
-	311041:  true,
-	313041:  true,
-	317041:  true,
-	357043:  true,
-	360043:  true,
-	362041:  true,
-	367041:  true,
-	369041:  true,
-	370041:  true,
-	376041:  true,
-	382045:  true,
-	384041:  true,
-	384043:  true,
-	384044:  true,
-	391041:  true,
-	417042:  true,
-	419042:  true,
-	424041:  true,
-	438042:  true,
-	444041:  true,
-	444043:  true,
-	445042:  true,
-	460042:  true,
-	461041:  true,
-	462043:  true,
-	466042:  true,
-	476043:  true,
-	484041:  true,
-	554043:  true,
-	556041:  true,
-	562041:  true,
-	569044:  true,
-	570041:  true,
-	576041:  true,
-	576042:  true,
-	578042:  true,
-	579041:  true,
-	581041:  true,
-	582041:  true,
-	582043:  true,
-	583041:  true,
-	586043:  true,
-	589043:  true,
-	593043:  true,
-	595041:  true,
-	597041:  true,
-	600041:  true,
-	601042:  true,
-	604043:  true,
-	606041:  true,
-	607041:  true,
-	608041:  true,
-	609042:  true,
-	610043:  true,
-	611042:  true,
-	614042:  true,
-	621041:  true,
-	622041:  true,
-	624041:  true,
-	624042:  true,
-	626042:  true,
-	627045:  true,
-	630043:  true,
-	634042:  true,
-	634044:  true,
-	634045:  true,
-	642041:  true,
-	642042:  true,
-	643042:  true,
-	645044:  true,
-	648041:  true,
-	649041:  true,
-	650042:  true,
-	651041:  true,
-	656043:  true,
-	657042:  true,
-	660041:  true,
-	661041:  true,
-	661043:  true,
-	661044:  true,
-	662041:  true,
-	664041:  true,
-	669041:  true,
-	673042:  true,
-	680042:  true,
-	682041:  true,
-	684041:  true,
-	691041:  true,
-	692041:  true,
-	695041:  true,
-	699041:  true,
-	700041:  true,
-	702042:  true,
-	703041:  true,
-	704041:  true,
-	705043:  true,
-	708041:  true,
-	711041:  true,
-	713041:  true,
-	714041:  true,
-	715042:  true,
-	719041:  true,
-	720041:  true,
-	725042:  true,
-	731041:  true,
-	733041:  true,
-	734041:  true,
-	738041:  true,
-	739042:  true,
-	740041:  true,
-	741041:  true,
-	743041:  true,
-	744042:  true,
-	747041:  true,
-	748041:  true,
-	749041:  true,
-	751041:  true,
-	755041:  true,
-	758041:  true,
-	759041:  true,
-	760042:  true,
-	762041:  true,
-	763041:  true,
-	763042:  true,
-	769041:  true,
-	770041:  true,
-	773041:  true,
-	778041:  true,
-	781041:  true,
-	782041:  true,
-	783041:  true,
-	784041:  true,
-	785041:  true,
-	786041:  true,
-	787041:  true,
-	788041:  true,
-	790041:  true,
-	791041:  true,
-	793041:  true,
-	799048:  true,
-	800041:  true,
-	800042:  true,
-	802043:  true,
-	802044:  true,
-	802051:  true,
-	804042:  true,
-	805043:  true,
-	808041:  true,
-	809041:  true,
-	810043:  true,
-	811042:  true,
-	811044:  true,
-	811046:  true,
-	812041:  true,
-	812043:  true,
-	812044:  true,
-	813043:  true,
-	813047:  true,
-	815041:  true,
-	815044:  true,
-	816042:  true,
-	816047:  true,
-	819042:  true,
-	820045:  true,
-	821044:  true,
-	821045:  true,
-	821046:  true,
-	821048:  true,
-	822044:  true,
-	822045:  true,
-	822047:  true,
-	823041:  true,
-	823045:  true,
-	823048:  true,
-	824041:  true,
-	824043:  true,
-	824045:  true,
-	824046:  true,
-	824049:  true,
-	824051:  true,
-	825043:  true,
-	826042:  true,
-	827042:  true,
-	829044:  true,
-	831042:  true,
-	831045:  true,
-	833042:  true,
-	833044:  true,
-	833045:  true,
-	834045:  true,
-	834046:  true,
-	836043:  true,
-	836046:  true,
-	837041:  true,
-	837042:  true,
-	838046:  true,
-	839041:  true,
-	840043:  true,
-	840045:  true,
-	842041:  true,
-	842042:  true,
-	843041:  true,
-	844041:  true,
-	844044:  true,
-	844048:  true,
-	845041:  true,
-	846043:  true,
-	846050:  true,
-	848041:  true,
-	848042:  true,
-	849041:  true,
-	849044:  true,
-	849045:  true,
-	849049:  true,
-	850041:  true,
-	851041:  true,
-	851045:  true,
-	853042:  true,
-	854045:  true,
-	854046:  true,
-	854048:  true,
-	855043:  true,
-	855044:  true,
-	855046:  true,
-	857045:  true,
-	857048:  true,
-	859042:  true,
-	860041:  true,
-	862042:  true,
-	864041:  true,
-	864042:  true,
-	864044:  true,
-	867044:  true,
-	867046:  true,
-	869041:  true,
-	869045:  true,
-	870041:  true,
-	870044:  true,
-	871042:  true,
-	872041:  true,
-	872043:  true,
-	872045:  true,
-	872048:  true,
-	874041:  true,
-	874043:  true,
-	875041:  true,
-	875043:  true,
-	875045:  true,
-	875048:  true,
-	876045:  true,
-	878046:  true,
-	878047:  true,
-	879041:  true,
-	880043:  true,
-	880046:  true,
-	881042:  true,
-	881043:  true,
-	881044:  true,
-	881047:  true,
-	881050:  true,
-	882043:  true,
-	882047:  true,
-	882049:  true,
-	883045:  true,
-	883046:  true,
-	883049:  true,
-	883051:  true,
-	884041:  true,
-	886043:  true,
-	887042:  true,
-	887043:  true,
-	887045:  true,
-	888045:  true,
-	889041:  true,
-	890044:  true,
-	890048:  true,
-	891048:  true,
-	891049:  true,
-	891050:  true,
-	893041:  true,
-	895043:  true,
-	896041:  true,
-	896045:  true,
-	897041:  true,
-	897042:  true,
-	901042:  true,
-	902041:  true,
-	902042:  true,
-	902044:  true,
-	902045:  true,
-	903043:  true,
-	903044:  true,
-	904046:  true,
-	906041:  true,
-	906042:  true,
-	907041:  true,
-	908041:  true,
-	908044:  true,
-	909041:  true,
-	912041:  true,
-	915041:  true,
-	917042:  true,
-	919045:  true,
-	920041:  true,
-	921041:  true,
-	929041:  true,
-	937042:  true,
-	940041:  true,
-	943043:  true,
-	943046:  true,
-	943047:  true,
-	944043:  true,
-	944044:  true,
-	946043:  true,
-	946045:  true,
-	946046:  true,
-	947042:  true,
-	949043:  true,
-	952041:  true,
-	953041:  true,
-	953042:  true,
-	954041:  true,
-	955041:  true,
-	956050:  true,
-	957041:  true,
-	957042:  true,
-	957045:  true,
-	958041:  true,
-	961041:  true,
-	961042:  true,
-	961044:  true,
-	961046:  true,
-	961047:  true,
-	962043:  true,
-	962045:  true,
-	962046:  true,
-	963042:  true,
-	963043:  true,
-	963044:  true,
-	964045:  true,
-	965044:  true,
-	965045:  true,
-	965047:  true,
-	967041:  true,
-	968041:  true,
-	968042:  true,
-	968043:  true,
-	969047:  true,
-	970041:  true,
-	970042:  true,
-	971041:  true,
-	972044:  true,
-	972045:  true,
-	972046:  true,
-	974043:  true,
-	975042:  true,
-	976041:  true,
-	978042:  true,
-	978043:  true,
-	979043:  true,
-	979044:  true,
-	980043:  true,
-	982043:  true,
-	986044:  true,
-	987041:  true,
-	988042:  true,
-	988043:  true,
-	989042:  true,
-	989044:  true,
-	989045:  true,
-	993042:  true,
-	994043:  true,
-	994044:  true,
-	1004042: true,
-	1004043: true,
-	1004045: true,
-	1006041: true,
-	1006044: true,
-	1006045: true,
-	1007041: true,
-	1008041: true,
-	1008042: true,
-	1008045: true,
-	1009041: true,
-	1010041: true,
-	1010042: true,
-	1013041: true,
-	1013042: true,
-	1014041: true,
-	1014043: true,
-	1015043: true,
-	1016041: true,
-	1018042: true,
-	1019042: true,
-	1019043: true,
-	1021041: true,
-	1021043: true,
-	1032041: true,
-	1032043: true,
-	1032044: true,
-	1037041: true,
-	1040041: true,
-	1040042: true,
-	1043042: true,
-	1044041: true,
-	1046042: true,
-	1048041: true,
-	1052042: true,
-	1054041: true,
-	1063042: true,
-	1064041: true,
-	1065041: true,
-	1067042: true,
-	1072041: true,
-	1073041: true,
-	1077041: true,
-	1078041: true,
-	1079041: true,
-	1080041: true,
-	1081041: true,
-	1081042: true,
-	1083041: true,
-	1084041: true,
-	1090041: true,
-	1091041: true,
-	1092041: true,
-	1094041: true,
-	1094043: true,
-	1098041: true,
-	1103041: true,
-	1112041: true,
-	1113041: true,
-	1118041: true,
-	1120042: true,
-	1121041: true,
-	1121042: true,
-	1122043: true,
-	1123043: true,
-	1127041: true,
-	1132042: true,
-	1133043: true,
-	1134041: true,
-	1138041: true,
-	1139042: true,
-	1140041: true,
-	1140043: true,
-	1145044: true,
-	1146041: true,
-	1148041: true,
-	1149041: true,
-	1154042: true,
-	1164041: true,
-	1166041: true,
-	1170041: true,
-	1171041: true,
-	1172042: true,
-	1173041: true,
-	1179041: true,
-	1180042: true,
-	1186041: true,
-	1193046: true,
-	1195041: true,
-	1195042: true,
-	1196043: true,
-	1198044: true,
-	1198046: true,
-	1202042: true,
-	1206043: true,
-	1207041: true,
-	1207043: true,
-	1207044: true,
-	1209047: true,
-	1209048: true,
-	1211047: true,
-	1216042: true,
-	1217046: true,
-	1218042: true,
-	1220041: true,
-	1220046: true,
-	1221042: true,
-	1221043: true,
-	1223044: true,
-	1224044: true,
-	1224045: true,
-	1227042: true,
-	1228041: true,
-	1228044: true,
-	1229047: true,
-	1230042: true,
-	1231041: true,
-	1231044: true,
-	1233041: true,
-	1235041: true,
-	1236043: true,
-	1238044: true,
-	1239043: true,
-	1240044: true,
-	1240046: true,
-	1242045: true,
-	1243041: true,
-	1243044: true,
-	1244044: true,
-	1244045: true,
-	1249043: true,
-	1250043: true,
-	1252045: true,
-	1253043: true,
-	1253046: true,
-	1254044: true,
-	1256042: true,
-	1257041: true,
-	1257043: true,
-	1258042: true,
-	1258044: true,
-	1259042: true,
-	1260041: true,
-	1262042: true,
-	1265042: true,
-	1266042: true,
-	1267041: true,
-	1267042: true,
-	1268041: true,
-	1270041: true,
-	1270042: true,
-	1271041: true,
-	1273042: true,
-	1274042: true,
-	1275042: true,
-	1278041: true,
-	1278042: true,
-	1279041: true,
-	1280041: true,
-	1281041: true,
-	1281042: true,
-	1282041: true,
-	1283041: true,
-	1284041: true,
-	1290041: true,
-	1301043: true,
-	1303041: true,
-	1303042: true,
-	1309045: true,
-	1315042: true,
-	1316042: true,
-	1319042: true,
-	1326042: true,
-	1331041: true,
-	1354041: true,
-	1363041: true,
-	1365041: true,
-	1372042: true,
-	1376042: true,
-	1381041: true,
-	1382041: true,
-	1383041: true,
-	1388041: true,
-	1390041: true,
-	1395041: true,
-	1399041: true,
-	1400041: true,
-	1404041: true,
-	1417041: true,
-	1418041: true,
-	1421041: true,
-	1426042: true,
-	1432041: true,
-	1433041: true,
-	1433042: true,
-	1434041: true,
-	1435041: true,
-	1436041: true,
-	1442042: true,
-	1444041: true,
-	1451044: true,
-	1452041: true,
-	1453041: true,
-	1454041: true,
-	1458041: true,
-	1462041: true,
-	1465041: true,
-	1472042: true,
-	1473042: true,
-	1475041: true,
-	1476041: true,
-	1480041: true,
-	1486041: true,
-	1495041: true,
-	1496042: true,
-	1499041: true,
-	1502041: true,
-	1503041: true,
-	1514041: true,
-	1518042: true,
-	1521042: true,
-	1522041: true,
-	1532041: true,
-	1533041: true,
-	1535041: true,
-	1536041: true,
-	1539042: true,
-	1548042: true,
-	1555042: true,
-	1559041: true,
-	1563041: true,
-	1567041: true,
-	1578041: true,
-	1581041: true,
-	1585041: true,
-	1590041: true,
-	1592041: true,
-	1592045: true,
-	1593041: true,
-	1593042: true,
-	1594041: true,
-	1597043: true,
-	1597044: true,
-	1599043: true,
-	1600041: true,
-	1610042: true,
-	1612041: true,
-	1613041: true,
-	1613045: true,
-	1614041: true,
-	1615042: true,
-	1615043: true,
-	1616041: true,
-	1616042: true,
-	1619042: true,
-	1620042: true,
-	1621041: true,
-	1623041: true,
-	1626043: true,
-	1627042: true,
-	1631041: true,
-	1632041: true,
-	1633042: true,
-	1636043: true,
-	1641043: true,
-	1644041: true,
-	1644042: true,
-	1647042: true,
-	1648043: true,
-	1649041: true,
-	1650041: true,
-	1652041: true,
-	1662041: true,
-	1663041: true,
-	1664043: true,
-	1664051: true,
-	1664053: true,
-	1664056: true,
-	1665043: true,
-	1665047: true,
-	1665051: true,
-	1666048: true,
-	1667048: true,
-	1667051: true,
-	1669055: true,
-	1669056: true,
-	1670046: true,
-	1670052: true,
-	1673049: true,
-	1674045: true,
-	1674049: true,
-	1675047: true,
-	1675048: true,
-	1675050: true,
-	1676054: true,
-	1677042: true,
-	1677043: true,
-	1677047: true,
-	1677049: true,
-	1677053: true,
-	1678046: true,
-	1678047: true,
-	1678048: true,
-	1678054: true,
-	1678063: true,
-	1679045: true,
-	1680042: true,
-	1680044: true,
-	1680045: true,
-	1680048: true,
-	1681049: true,
-	1682043: true,
-	1682048: true,
-	1682050: true,
-	1682052: true,
-	1684046: true,
-	1684051: true,
-	1684055: true,
-	1686044: true,
-	1686047: true,
-	1686054: true,
-	1687045: true,
-	1687047: true,
-	1689042: true,
-	1689046: true,
-	1689061: true,
-	1690043: true,
-	1690053: true,
-	1691045: true,
-	1692055: true,
-	1692057: true,
-	1693043: true,
-	1693046: true,
-	1693047: true,
-	1694044: true,
-	1695046: true,
-	1695049: true,
-	1696044: true,
-	1696051: true,
-	1696062: true,
-	1697048: true,
-	1697053: true,
-	1697057: true,
-	1698041: true,
-	1698043: true,
-	1698045: true,
-	1698046: true,
-	1698051: true,
-	1699045: true,
-	1699046: true,
-	1699050: true,
-	1700043: true,
-	1700048: true,
-	1701041: true,
-	1701045: true,
-	1701048: true,
-	1701051: true,
-	1702045: true,
-	1702050: true,
-	1703041: true,
-	1704041: true,
-	1704044: true,
-	1704046: true,
-	1705041: true,
-	1706043: true,
-	1706044: true,
-	1706053: true,
-	1707042: true,
-	1707043: true,
-	1707047: true,
-	1708046: true,
-	1708048: true,
-	1712042: true,
-	1712048: true,
-	1714044: true,
-	1715043: true,
-	1715046: true,
-	1715054: true,
-	1715060: true,
-	1717046: true,
-	1718042: true,
-	1720043: true,
-	1722046: true,
-	1723044: true,
-	1724042: true,
-	1727043: true,
-	1729045: true,
-	1729046: true,
-	1729051: true,
-	1729052: true,
-	1731043: true,
-	1731047: true,
-	1731048: true,
-	1731057: true,
-	1732043: true,
-	1733045: true,
-	1733046: true,
-	1734042: true,
-	1734046: true,
-	1734047: true,
-	1736041: true,
-	1736042: true,
-	1737044: true,
-	1738047: true,
-	1738048: true,
-	1739042: true,
-	1739043: true,
-	1740043: true,
-	1740044: true,
-	1741041: true,
-	1741045: true,
-	1741047: true,
-	1741053: true,
-	1741058: true,
-	1741059: true,
-	1742044: true,
-	1742045: true,
-	1742048: true,
-	1742050: true,
-	1742055: true,
-	1742057: true,
-	1743053: true,
-	1743059: true,
-	1745041: true,
-	1745042: true,
-	1746047: true,
-	1747041: true,
-	1748041: true,
-	1748042: true,
-	1749041: true,
-	1750042: true,
-	1752043: true,
-	1752044: true,
-	1756042: true,
-	1760043: true,
-	1761042: true,
-	1762042: true,
-	1762044: true,
-	1764043: true,
-	1766042: true,
-	1771043: true,
-	1772043: true,
-	1773041: true,
-	1774044: true,
-	1774047: true,
-	1777041: true,
-	1778041: true,
-	1780042: true,
-	1784042: true,
-	1790041: true,
-	1791041: true,
-	1792042: true,
-	1803043: true,
-	1804041: true,
-	1805041: true,
-	1806043: true,
-	1812043: true,
-	1813042: true,
-	1814043: true,
-	1814044: true,
-	1815042: true,
-	1817041: true,
-	1819041: true,
-	1821041: true,
-	1823041: true,
-	1824042: true,
-	1825043: true,
-	1830041: true,
-	1835041: true,
-	1838041: true,
-	1841042: true,
-	1841044: true,
-	1842042: true,
-	1843044: true,
-	1843046: true,
-	1844041: true,
-	1846042: true,
-	1846043: true,
-	1846047: true,
-	1846051: true,
-	1847041: true,
-	1847042: true,
-	1847043: true,
-	1847047: true,
-	1847050: true,
-	1847051: true,
-	1848045: true,
-	1848047: true,
-	1848049: true,
-	1848056: true,
-	1849041: true,
-	1849054: true,
-	1849055: true,
-	1850052: true,
-	1853041: true,
-	1854042: true,
-	1855043: true,
-	1855051: true,
-	1856042: true,
-	1856047: true,
-	1856052: true,
-	1856054: true,
-	1857043: true,
-	1857048: true,
-	1857049: true,
-	1859044: true,
-	1860045: true,
-	1860046: true,
-	1862042: true,
-	1862043: true,
-	1862044: true,
-	1862045: true,
-	1862046: true,
-	1863042: true,
-	1863046: true,
-	1863051: true,
-	1864042: true,
-	1864046: true,
-	1864051: true,
-	1866044: true,
-	1867042: true,
-	1868041: true,
-	1868043: true,
-	1868047: true,
-	1869042: true,
-	1869043: true,
-	1870042: true,
-	1870047: true,
-	1871049: true,
-	1871054: true,
-	1871055: true,
-	1871057: true,
-	1872041: true,
-	1872042: true,
-	1872045: true,
-	1873047: true,
-	1874045: true,
-	1874046: true,
-	1875043: true,
-	1875049: true,
-	1876045: true,
-	1879043: true,
-	1880047: true,
-	1882043: true,
-	1882046: true,
-	1883049: true,
-	1886042: true,
-	1886043: true,
-	1886046: true,
-	1888041: true,
-	1889041: true,
-	1890043: true,
-	1890045: true,
-	1892049: true,
-	1892050: true,
-	1892052: true,
-	1893042: true,
-	1893045: true,
-	1894043: true,
-	1894047: true,
-	1895049: true,
-	1896044: true,
-	1897043: true,
-	1897046: true,
-	1897049: true,
-	1897050: true,
-	1899041: true,
-	1899042: true,
-	1899044: true,
-	1900044: true,
-	1902042: true,
-	1902043: true,
-	1902049: true,
-	1903043: true,
-	1903049: true,
-	1904041: true,
-	1904044: true,
-	1904045: true,
-	1905050: true,
-	1906042: true,
-	1907041: true,
-	1907045: true,
-	1907046: true,
-	1910041: true,
-	1910045: true,
-	1912042: true,
-	1913041: true,
-	1913042: true,
-	1913047: true,
-	1914041: true,
-	1914046: true,
-	1915042: true,
-	1915043: true,
-	1917044: true,
-	1917048: true,
-	1918047: true,
-	1919042: true,
-	1923041: true,
-	1923043: true,
-	1933043: true,
-	1933044: true,
-	1933045: true,
-	1935041: true,
-	1936044: true,
-	1938041: true,
-	1938044: true,
-	1939042: true,
-	1940042: true,
-	1941041: true,
-	1941042: true,
-	1941052: true,
-	1942043: true,
-	1942044: true,
-	1943042: true,
-	1943045: true,
-	1943049: true,
-	1944043: true,
-	1946041: true,
-	1947041: true,
-	1947042: true,
-	1949042: true,
-	1950041: true,
-	1950045: true,
-	1951041: true,
-	1951043: true,
-	1952041: true,
-	1952045: true,
-	1952046: true,
-	1952047: true,
-	1953044: true,
-	1953048: true,
-	1954044: true,
-	1954049: true,
-	1955049: true,
-	1956044: true,
-	1956046: true,
-	1957041: true,
-	1957045: true,
-	1958047: true,
-	1958048: true,
-	1959042: true,
-	1959043: true,
-	1959044: true,
-	1960042: true,
-	1960044: true,
-	1960050: true,
-	1961042: true,
-	1962041: true,
-	1962042: true,
-	1962046: true,
-	1962048: true,
-	1963044: true,
-	1964043: true,
-	1965042: true,
-	1966048: true,
-	1967045: true,
-	1967047: true,
-	1967048: true,
-	1967049: true,
-	1968041: true,
-	1968042: true,
-	1968043: true,
-	1969042: true,
-	1969046: true,
-	1971042: true,
-	1972044: true,
-	1972046: true,
-	1973043: true,
-	1974046: true,
-	1975042: true,
-	1975043: true,
-	1975044: true,
-	1976044: true,
-	1976045: true,
-	1976049: true,
-	1981041: true,
-	1981044: true,
-	1981047: true,
-	1982041: true,
-	1982042: true,
-	1982045: true,
-	1982049: true,
-	1983041: true,
-	1983042: true,
-	1983043: true,
-	1983045: true,
-	1983050: true,
-	1983051: true,
-	1984043: true,
-	1985042: true,
-	1986044: true,
-	1987041: true,
-	1987042: true,
-	1991047: true,
-	1993043: true,
-	1994041: true,
-	1995041: true,
-	1995043: true,
-	1995050: true,
-	1995051: true,
-	1997044: true,
-	1997045: true,
-	1998041: true,
-	1998042: true,
-	1998045: true,
-	1998046: true,
-	1999044: true,
-	2000041: true,
-	2004044: true,
-	2004046: true,
-	2005042: true,
-	2007042: true,
-	2007043: true,
-	2007047: true,
-	2009041: true,
-	2009043: true,
-	2009044: true,
-	2009045: true,
-	2009046: true,
-	2010041: true,
-	2011046: true,
-	2012041: true,
-	2013043: true,
-	2015043: true,
-	2017042: true,
-	2018041: true,
-	2020043: true,
-	2020045: true,
-	2021041: true,
-	2023042: true,
-	2023044: true,
-	2024043: true,
-	2025041: true,
-	2025042: true,
-	2026045: true,
-	2029042: true,
-	2033041: true,
-	2033043: true,
-	2036045: true,
-	2039043: true,
-	2039044: true,
-	2040041: true,
-	2040042: true,
-	2040043: true,
-	2041041: true,
-	2042044: true,
-	2043041: true,
-	2043042: true,
-	2048043: true,
-	2049043: true,
-	2050042: true,
-	2050043: true,
-	2051041: true,
-	2052041: true,
-	2052042: true,
-	2053041: true,
-	2055041: true,
-	2061042: true,
-	2066042: true,
-	2068041: true,
-	2072041: true,
-	2073041: true,
-	2075041: true,
-	2078041: true,
-	2083041: true,
-	2083042: true,
-	2084041: true,
-	2084042: true,
-	2091042: true,
-	2094041: true,
-	2095041: true,
-	2098044: true,
-	2098047: true,
-	2098048: true,
-	2099041: true,
-	2100041: true,
-	2100044: true,
-	2102043: true,
-	2103046: true,
-	2103048: true,
-	2104042: true,
-	2104048: true,
-	2105043: true,
-	2106047: true,
-	2106049: true,
-	2107047: true,
-	2107048: true,
-	2110044: true,
-	2110046: true,
-	2111045: true,
-	2111046: true,
-	2112041: true,
-	2112042: true,
-	2112046: true,
-	2113041: true,
-	2114042: true,
-	2115042: true,
-	2115043: true,
-	2115045: true,
-	2116043: true,
-	2116047: true,
-	2118050: true,
-	2119042: true,
-	2119044: true,
-	2119048: true,
-	2121041: true,
-	2121042: true,
-	2121045: true,
-	2121048: true,
-	2122042: true,
-	2122049: true,
-	2123044: true,
-	2124041: true,
-	2124044: true,
-	2124045: true,
-	2124046: true,
-	2125042: true,
-	2126042: true,
-	2127045: true,
-	2127046: true,
-	2128041: true,
-	2128045: true,
-	2128047: true,
-	2129041: true,
-	2132045: true,
-	2132046: true,
-	2132047: true,
-	2134045: true,
-	2135045: true,
-	2136041: true,
-	2136043: true,
-	2136047: true,
-	2137041: true,
-	2137048: true,
-	2138045: true,
-	2138047: true,
-	2139044: true,
-	2139045: true,
-	2140043: true,
-	2140045: true,
-	2140046: true,
-	2140048: true,
-	2141041: true,
-	2141042: true,
-	2141043: true,
-	2143041: true,
-	2144042: true,
-	2144043: true,
-	2145043: true,
-	2145045: true,
-	2146044: true,
-	2147042: true,
-	2147047: true,
-	2148042: true,
-	2149045: true,
-	2149046: true,
-	2149047: true,
-	2150042: true,
-	2150044: true,
-	2151042: true,
-	2151044: true,
-	2151046: true,
-	2152045: true,
-	2154041: true,
-	2156045: true,
-	2156046: true,
-	2156047: true,
-	2157041: true,
-	2157042: true,
-	2157043: true,
-	2158043: true,
-	2158044: true,
-	2160041: true,
-	2161043: true,
-	2162043: true,
-	2163042: true,
-	2163044: true,
-	2165042: true,
-	2165044: true,
-	2165046: true,
-	2166041: true,
-	2166042: true,
-	2167045: true,
-	2168043: true,
-	2169043: true,
-	2171044: true,
-	2172041: true,
-	2172043: true,
-	2174043: true,
-	2175041: true,
-	2178041: true,
-	2182041: true,
-	2182042: true,
-	2182043: true,
-	2184042: true,
-	2184044: true,
-	2186041: true,
-	2187042: true,
-	2188041: true,
-	2190044: true,
-	2191043: true,
-	2192041: true,
-	2192043: true,
-	2192048: true,
-	2192049: true,
-	2192050: true,
-	2193048: true,
-	2194041: true,
-	2194046: true,
-	2194047: true,
-	2197047: true,
-	2197050: true,
-	2198042: true,
-	2198044: true,
-	2198046: true,
-	2198047: true,
-	2199043: true,
-	2201041: true,
-	2201044: true,
-	2204045: true,
-	2204047: true,
-	2204048: true,
-	2204050: true,
-	2205042: true,
-	2206041: true,
-	2206042: true,
-	2206044: true,
-	2207044: true,
-	2207045: true,
-	2208046: true,
-	2208047: true,
-	2208049: true,
-	2208050: true,
-	2208053: true,
-	2209042: true,
-	2211041: true,
-	2211044: true,
-	2211045: true,
-	2212041: true,
-	2212042: true,
-	2212043: true,
-	2212044: true,
-	2213042: true,
-	2213044: true,
-	2213045: true,
-	2213048: true,
-	2214042: true,
-	2214043: true,
-	2214044: true,
-	2215044: true,
-	2215045: true,
-	2215046: true,
-	2216043: true,
-	2216047: true,
-	2216051: true,
-	2216053: true,
-	2216054: true,
-	2218043: true,
-	2218044: true,
-	2218046: true,
-	2220048: true,
-	2221042: true,
-	2221044: true,
-	2223041: true,
-	2224041: true,
-	2225041: true,
-	2225042: true,
-	2225044: true,
-	2225045: true,
-	2225046: true,
-	2225048: true,
-	2226046: true,
-	2226047: true,
-	2227041: true,
-	2228041: true,
-	2229041: true,
-	2229044: true,
-	2229047: true,
-	2233043: true,
-	2233044: true,
-	2234044: true,
-	2237042: true,
-	2237043: true,
-	2237044: true,
-	2240043: true,
-	2241043: true,
-	2241045: true,
-	2244041: true,
-	2244044: true,
-	2245041: true,
-	2246041: true,
-	2246042: true,
-	2246046: true,
-	2246049: true,
-	2247044: true,
-	2248041: true,
-	2248043: true,
-	2248045: true,
-	2248046: true,
-	2249041: true,
-	2249042: true,
-	2249045: true,
-	2250041: true,
-	2250042: true,
-	2253042: true,
-	2254041: true,
-	2254044: true,
-	2254047: true,
-	2255042: true,
-	2256041: true,
-	2256042: true,
-	2257042: true,
-	2258045: true,
-	2261042: true,
-	2264043: true,
-	2265041: true,
-	2265042: true,
-	2265044: true,
-	2266043: true,
-	2267042: true,
-	2267044: true,
-	2267046: true,
-	2270041: true,
-	2270042: true,
-	2271041: true,
-	2272041: true,
-	2273041: true,
-	2273042: true,
-	2275041: true,
-	2278041: true,
-	2279041: true,
-	2279042: true,
-	2288041: true,
-	2289041: true,
-	2290041: true,
-	2290044: true,
-	2290045: true,
-	2294041: true,
-	2295041: true,
-	2296041: true,
-	2297041: true,
-	2297042: true,
-	2298041: true,
-	2300041: true,
-	2301041: true,
-	2301045: true,
-	2302041: true,
-	2302042: true,
-	2303041: true,
-	2303043: true,
-	2305043: true,
-	2308042: true,
-	2308043: true,
-	2309043: true,
-	2310041: true,
-	2312041: true,
-	2312042: true,
-	2313041: true,
-	2314043: true,
-	2315042: true,
-	2315045: true,
-	2316044: true,
-	2317044: true,
-	2319041: true,
-	2321043: true,
-	2323042: true,
-	2326041: true,
-	2329041: true,
-	2329042: true,
-	2330041: true,
-	2330042: true,
-	2331044: true,
-	2331045: true,
-	2332042: true,
-	2333043: true,
-	2333045: true,
-	2335043: true,
-	2336042: true,
-	2336044: true,
-	2337044: true,
-	2338041: true,
-	2338042: true,
-	2339042: true,
-	2341042: true,
-	2341049: true,
-	2342045: true,
-	2343043: true,
-	2344041: true,
-	2344042: true,
-	2345041: true,
-	2345043: true,
-	2346044: true,
-	2350041: true,
-	2352043: true,
-	2353043: true,
-	2355043: true,
-	2356042: true,
-	2358041: true,
-	2362043: true,
-	2365044: true,
-	2367041: true,
-	2369043: true,
-	2370043: true,
-	2372042: true,
-	2373043: true,
-	2380042: true,
-	2383041: true,
-	2384042: true,
-	2385041: true,
-	2385042: true,
-	2387042: true,
-	2390041: true,
-	2390042: true,
-	2400041: true,
-	2404043: true,
-	2414041: true,
-	2416042: true,
-	2417042: true,
-	2419042: true,
-	2420041: true,
-	2421042: true,
-	2427042: true,
-	2431042: true,
-	2433042: true,
-	2436041: true,
-	2437041: true,
-	2438041: true,
-	2439041: true,
-	2443041: true,
-	2444041: true,
-	2447042: true,
-	2448041: true,
-	2449041: true,
-	2456041: true,
-	2465041: true,
-	2468041: true,
-	2469041: true,
-	2469043: true,
-	2470045: true,
-	2471042: true,
-	2471043: true,
-	2472041: true,
-	2473041: true,
-	2473042: true,
-	2475042: true,
-	2476041: true,
-	2479043: true,
-	2481042: true,
-	2485041: true,
-	2486043: true,
-	2487042: true,
-	2490041: true,
-	2490043: true,
-	2491041: true,
-	2493043: true,
-	2494042: true,
-	2496041: true,
-	2498041: true,
-	2499041: true,
-	2504043: true,
-	2506042: true,
-	2506045: true,
-	2512041: true,
-	2512042: true,
-	2514041: true,
-	2515042: true,
-	2515043: true,
-	2519042: true,
-	2519043: true,
-	2519044: true,
-	2520042: true,
-	2521042: true,
-	2525041: true,
-	2525042: true,
-	2526041: true,
-	2530041: true,
-	2532041: true,
-	2533041: true,
-	2537041: true,
-	2540042: true,
-	2542042: true,
-	2543042: true,
-	2543043: true,
-	2543044: true,
-	2550041: true,
-	2554042: true,
-	2555041: true,
-	2556041: true,
-	2566042: true,
-	2585043: true,
-	2587041: true,
-	2588041: true,
-	2592041: true,
-	2595041: true,
-	2596041: true,
-	2596043: true,
-	2597041: true,
-	2598041: true,
-	2606041: true,
-	2608041: true,
-	2609041: true,
-	2612041: true,
-	2613042: true,
-	2614041: true,
-	2615041: true,
-	2616041: true,
-	2618042: true,
-	2620041: true,
-	2621042: true,
-	2622041: true,
-	2623043: true,
-	2624042: true,
-	2627043: true,
-	2629041: true,
-	2631041: true,
-	2634041: true,
-	2635041: true,
-	2637041: true,
-	2638041: true,
-	2639041: true,
-	2645042: true,
-	2649041: true,
-	2650041: true,
-	2652042: true,
-	2654041: true,
-	2658042: true,
-	2660042: true,
-	2661041: true,
-	2662041: true,
-	2663041: true,
-	2664041: true,
-	2666041: true,
-	2670042: true,
-	2674041: true,
-	2678041: true,
-	2683041: true,
-	2685042: true,
-	2687042: true,
-	2695042: true,
-	2696041: true,
-	2700041: true,
-	2701041: true,
-	2701042: true,
-	2704042: true,
-	2710044: true,
-	2711043: true,
-	2713042: true,
-	2713043: true,
-	2716041: true,
-	2717043: true,
-	2720042: true,
-	2723041: true,
-	2726041: true,
-	2727041: true,
-	2728043: true,
-	2729041: true,
-	2731041: true,
-	2732041: true,
-	2732042: true,
-	2733041: true,
-	2733042: true,
-	2735041: true,
-	2735042: true,
-	2735043: true,
-	2736041: true,
-	2736044: true,
-	2737041: true,
-	2738041: true,
-	2740041: true,
-	2741041: true,
-	2741042: true,
-	2743041: true,
-	2743042: true,
-	2747044: true,
-	2748041: true,
-	2749041: true,
-	2750041: true,
-	2751042: true,
-	2753043: true,
-	2753045: true,
-	2754043: true,
-	2757042: true,
-	2759041: true,
-	2761041: true,
-	2761043: true,
-	2762041: true,
-	2762042: true,
-	2763041: true,
-	2768042: true,
-	2770044: true,
-	2774041: true,
-	2777041: true,
-	2778042: true,
-	2781042: true,
-	2782041: true,
-	2789043: true,
-	2791042: true,
-	2792041: true,
-	2793041: true,
-	2795041: true,
-	2798041: true,
-	2799041: true,
-	2801042: true,
-	2804041: true,
-	2806041: true,
-	2807041: true,
-	2813041: true,
-	2814041: true,
-	2816041: true,
-	2817041: true,
-	2818041: true,
-	2819041: true,
-	2820041: true,
-	2821041: true,
-	2823042: true,
-	2825041: true,
-	2831041: true,
-	2831042: true,
-	2833041: true,
-	2834041: true,
-	2836041: true,
-	2841041: true,
-	2846041: true,
-	2848041: true,
-	2853042: true,
-	2859041: true,
-	2865041: true,
-	2880041: true,
-	2881042: true,
-	2886041: true,
-	2888041: true,
-	2889042: true,
-	2890041: true,
-	2894042: true,
-	2899041: true,
-	2900041: true,
-	2904041: true,
-	2906041: true,
-	2907041: true,
-	2908041: true,
-	2916041: true,
-	2918042: true,
-	2922041: true,
-	2923041: true,
-	2925041: true,
-	2932041: true,
-	2934041: true,
-	2936041: true,
-	2937041: true,
-	2938041: true,
-	2939041: true,
-	2942042: true,
-	2943041: true,
-	2967041: true,
-	2970042: true,
-	2984041: true,
-	2985041: true,
-	2985042: true,
-	2985043: true,
-	2986042: true,
-	2988041: true,
-	2990041: true,
-	2991041: true,
-	2993041: true,
-	2994041: true,
-	2997041: true,
-	3000041: true,
-	3002041: true,
-	3003043: true,
-	3008041: true,
-	3015042: true,
-	3016041: true,
-	3016043: true,
-	3022041: true,
-	3024041: true,
-	3025042: true,
-	3026041: true,
-	3042041: true,
-	3044041: true,
-	3048041: true,
-	3050041: true,
-	3051041: true,
-	3052041: true,
-	3053041: true,
-	3057041: true,
-	3065041: true,
-	3069041: true,
-	3071041: true,
-	3072041: true,
-	3074041: true,
-	3075041: true,
-	3076041: true,
-	3079041: true,
-	3095042: true,
-	3103042: true,
-	3136042: true,
-	3146041: true,
-	3150041: true,
-	3152041: true,
-	3159041: true,
-	3182043: true,
-	3183041: true,
-	3183043: true,
-	3195042: true,
-	3200041: true,
-	3205041: true,
-	3206041: true,
-	3207041: true,
-	3208041: true,
-	3209041: true,
-	3210041: true,
-	3213041: true,
-	3217042: true,
-	3225042: true,
-	3231041: true,
-	3231042: true,
-	3245041: true,
-	3259043: true,
-	3266042: true,
-	3268042: true,
-	3271041: true,
-	3280044: true,
-	3280045: true,
-	3283042: true,
-	3287042: true,
-	3288042: true,
-	3293041: true,
-	3294041: true,
-	3299041: true,
-	3300042: true,
-	3302042: true,
-	3306042: true,
-	3308041: true,
-	3309041: true,
-	3311043: true,
-	3315041: true,
-	3316041: true,
-	3319042: true,
-	3332043: true,
-	3334041: true,
-	3344044: true,
-	3352041: true,
-	3355041: true,
-	3359041: true,
-	3359042: true,
-	3361041: true,
-	3364041: true,
-	3366041: true,
-	3373041: true,
-	3378041: true,
-	3385041: true,
-	3391042: true,
-	3391043: true,
-	3391044: true,
-	3395041: true,
-	3395042: true,
-	3399042: true,
-	3411043: true,
-	3415042: true,
-	3416042: true,
-	3416043: true,
-	3417041: true,
-	3417042: true,
-	3418043: true,
-	3419043: true,
-	3420041: true,
-	3420043: true,
-	3421041: true,
-	3421042: true,
-	3423041: true,
-	3423043: true,
-	3425044: true,
-	3426042: true,
-	3427043: true,
-	3430042: true,
-	3431045: true,
-	3431046: true,
-	3433041: true,
-	3433042: true,
-	3434041: true,
-	3434046: true,
-	3435042: true,
-	3437041: true,
-	3439043: true,
-	3440046: true,
-	3442041: true,
-	3442042: true,
-	3444043: true,
-	3445041: true,
-	3448042: true,
-	3448043: true,
-	3449044: true,
-	3453042: true,
-	3459043: true,
-	3460042: true,
-	3460043: true,
-	3463043: true,
-	3466044: true,
-	3469044: true,
-	3470044: true,
-	3473041: true,
-	3473044: true,
-	3477041: true,
-	3481041: true,
-	3481042: true,
-	3481043: true,
-	3483041: true,
-	3485044: true,
-	3485046: true,
-	3488041: true,
-	3491042: true,
-	3497042: true,
-	3499041: true,
-	3502041: true,
-	3503041: true,
-	3504041: true,
-	3504042: true,
-	3505041: true,
-	3506041: true,
-	3507041: true,
-	3508041: true,
-	3510041: true,
-	3511043: true,
-	3514041: true,
-	3515041: true,
-	3516042: true,
-	3521041: true,
-	3522041: true,
-	3523041: true,
-	3524041: true,
-	3527042: true,
-	3529041: true,
-	3529042: true,
-	3532042: true,
-	3533041: true,
-	3536041: true,
-	3536043: true,
-	3540042: true,
-	3541044: true,
-	3545042: true,
-	3545044: true,
-	3547041: true,
-	3547042: true,
-	3549041: true,
-	3553041: true,
-	3555041: true,
-	3556041: true,
-	3559041: true,
-	3561041: true,
-	3562042: true,
-	3563042: true,
-	3564041: true,
-	3565041: true,
-	3571043: true,
-	3572042: true,
-	3573043: true,
-	3573044: true,
-	3574044: true,
-	3575042: true,
-	3576043: true,
-	3578041: true,
-	3579042: true,
-	3580041: true,
-	3580042: true,
-	3582042: true,
-	3584042: true,
-	3585041: true,
-	3592041: true,
-	3595043: true,
-	3601041: true,
-	3606043: true,
-	3608042: true,
-	3609042: true,
-	3617041: true,
-	3618041: true,
-	3619041: true,
-	3619042: true,
-	3620041: true,
-	3621041: true,
-	3627041: true,
-	3628041: true,
-	3631041: true,
-	3634042: true,
-	3637041: true,
-	3641041: true,
-	3641042: true,
-	3642041: true,
-	3646043: true,
-	3650042: true,
-	3652041: true,
-	3659041: true,
-	3661042: true,
-	3666042: true,
-	3668042: true,
-	3671042: true,
-	3674043: true,
-	3676041: true,
-	3680041: true,
-	3683042: true,
-	3685041: true,
-	3685043: true,
-	3687041: true,
-	3690042: true,
-	3691042: true,
-	3699041: true,
-	3701041: true,
-	3702041: true,
-	3705041: true,
-	3707041: true,
-	3708041: true,
-	3709041: true,
-	3710042: true,
-	3712041: true,
-	3719042: true,
-	3720042: true,
-	3723041: true,
-	3728041: true,
-	3730041: true,
-	3731041: true,
-	3731046: true,
-	3731048: true,
-	3733046: true,
-	3739042: true,
-	3746041: true,
-	3746044: true,
-	3746047: true,
-	3749041: true,
-	3749043: true,
-	3749044: true,
-	3750041: true,
-	3751041: true,
-	3752041: true,
-	3752044: true,
-	3753041: true,
-	3759042: true,
-	3760049: true,
-	3763043: true,
-	3766042: true,
-	3766045: true,
-	3769042: true,
-	3770045: true,
-	3772043: true,
-	3775047: true,
-	3775048: true,
-	3775050: true,
-	3779043: true,
-	3782044: true,
-	3786043: true,
-	3787044: true,
-	3787046: true,
-	3789045: true,
-	3790044: true,
-	3794041: true,
-	3794043: true,
-	3795044: true,
-	3797041: true,
-	3797045: true,
-	3799045: true,
-	3802044: true,
-	3802045: true,
-	3804042: true,
-	3809041: true,
-	3809042: true,
-	3811042: true,
-	3813045: true,
-	3814041: true,
-	3816043: true,
-	3818044: true,
-	3821042: true,
-	3821044: true,
-	3824043: true,
-	3827042: true,
-	3827043: true,
-	3829043: true,
-	3831041: true,
-	3832045: true,
-	3834042: true,
-	3835043: true,
-	3837042: true,
-	3849043: true,
-	3849044: true,
-	3851041: true,
-	3851042: true,
-	3852041: true,
-	3858042: true,
-	3861044: true,
-	3862041: true,
-	3867041: true,
-	3867044: true,
-	3867045: true,
-	3868041: true,
-	3869042: true,
-	3869043: true,
-	3879041: true,
-	3880041: true,
-	3881041: true,
-	3882042: true,
-	3885041: true,
-	3886041: true,
-	3887042: true,
-	3889043: true,
-	3890042: true,
-	3891042: true,
-	3892041: true,
-	3896042: true,
-	3898042: true,
-	3900044: true,
-	3901043: true,
-	3902042: true,
-	3902044: true,
-	3903043: true,
-	3905041: true,
-	3906041: true,
-	3907042: true,
-	3909041: true,
-	3910042: true,
-	3912041: true,
-	3912043: true,
-	3913041: true,
-	3913043: true,
-	3918041: true,
-	3919043: true,
-	3920042: true,
-	3921041: true,
-	3921043: true,
-	3923043: true,
-	3926041: true,
-	3926044: true,
-	3937041: true,
-	3937043: true,
-	3939042: true,
-	3941041: true,
-	3946041: true,
-	3948043: true,
-	3949042: true,
-	3952041: true,
-	3956042: true,
-	3959041: true,
-	3967041: true,
-	3969042: true,
-	3970041: true,
-	3970045: true,
-	3971041: true,
-	3971044: true,
-	3972041: true,
-	3972047: true,
-	3973041: true,
-	3973050: true,
-	3973051: true,
-	3973052: true,
-	3973054: true,
-	3974044: true,
-	3975046: true,
-	3978042: true,
-	3978043: true,
-	3979044: true,
-	3979045: true,
-	3980042: true,
-	3980043: true,
-	3981043: true,
-	3981049: true,
-	3981052: true,
-	3981054: true,
-	3982045: true,
-	3982051: true,
-	3984042: true,
-	3985046: true,
-	3985047: true,
-	3986042: true,
-	3987043: true,
-	3987045: true,
-	3988045: true,
-	3989042: true,
-	3989052: true,
-	3989056: true,
-	3989058: true,
-	3989059: true,
-	3989061: true,
-	3989062: true,
-	3989065: true,
-	3990043: true,
-	3990045: true,
-	3991043: true,
-	3991045: true,
-	3991047: true,
-	3992041: true,
-	3992044: true,
-	3992047: true,
-	3993041: true,
-	3993044: true,
-	3994041: true,
-	3994042: true,
-	3994043: true,
-	3996047: true,
-	3998041: true,
-	3998045: true,
-	3998053: true,
-	3999041: true,
-	3999046: true,
-	3999047: true,
-}
@@ -0,0 +1,363 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package redirect
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// A Rule is a single redirect rule loaded from a rules file.
+//
+// Exactly one of Path, Prefix, and Regexp must be set.
+// A Path rule matches a request whose URL path is exactly Path
+// and redirects to To.
+// A Prefix rule matches a request whose URL path begins with Prefix
+// and redirects to To followed by the remainder of the path.
+// A Regexp rule matches a request whose URL path matches the regular expression
+// and redirects to To with $1, $2, and so on replaced by the submatches
+// (see regexp.Regexp.Expand).
+// In all cases the request's query string, if any, is appended to the target.
+//
+// If Host is set, the rule only applies to requests for that host.
+// Status is the HTTP redirect status code; it defaults to 301 (moved permanently).
+type Rule struct {
+	Host   string `yaml:"host"`
+	Path   string `yaml:"path"`
+	Prefix string `yaml:"prefix"`
+	Regexp string `yaml:"regexp"`
+	To     string `yaml:"to"`
+	Status int    `yaml:"status"`
+
+	line int            // line number in rules file
+	re   *regexp.Regexp // compiled Regexp
+}
+
+func (r *Rule) String() string {
+	s := r.Path
+	switch {
+	case r.Prefix != "":
+		s = r.Prefix + "*"
+	case r.Regexp != "":
+		s = "~" + r.Regexp
+	}
+	return r.Host + s + " -> " + r.To
+}
+
+// target returns the redirect target for the request path p on host,
+// or "", false if the rule does not apply.
+func (r *Rule) target(host, p string) (string, bool) {
+	if r.Host != "" && r.Host != host {
+		return "", false
+	}
+	switch {
+	case r.Path != "":
+		if p == r.Path {
+			return r.To, true
+		}
+	case r.Prefix != "":
+		if strings.HasPrefix(p, r.Prefix) {
+			return r.To + p[len(r.Prefix):], true
+		}
+	case r.re != nil:
+		if m := r.re.FindStringSubmatchIndex(p); m != nil {
+			return string(r.re.ExpandString(nil, r.To, p, m)), true
+		}
+	}
+	return "", false
+}
+
+// A RuleSet is a validated, ordered set of redirect rules.
+//
+// Lookup consults exact Path rules first, then Prefix rules
+// (longest prefix first), then Regexp rules in file order.
+// Within each kind, a rule for a specific Host takes
+// precedence over a rule that applies to all hosts.
+type RuleSet struct {
+	rules []*Rule
+}
+
+// ParseRules parses and validates the YAML rules in data,
+// reporting errors as being from file.
+// The data is a list of rules, such as:
+//
+//	# redirects.yaml
+//	- path: /issues
+//	  to: https://github.com/goplus/gop/issues
+//	- prefix: /wiki/
+//	  to: https://github.com/goplus/gop/wiki/
+//	  status: 302
+//	- regexp: ^/doc/articles/(.*)\.html$
+//	  to: /doc/$1
+//
+// ParseRules rejects rules that conflict with each other
+// (the same path or prefix listed twice for the same host)
+// and sets of rules that redirect in a loop.
+// Loops are only detected starting from exact path and prefix rules.
+func ParseRules(file string, data []byte) (*RuleSet, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	var list []*Rule
+	if len(doc.Content) > 0 {
+		if err := doc.Content[0].Decode(&list); err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		for i, n := range doc.Content[0].Content {
+			if i < len(list) && list[i] != nil {
+				list[i].line = n.Line
+			}
+		}
+	}
+
+	var errs []string
+	errorf := func(r *Rule, format string, args ...interface{}) {
+		errs = append(errs, fmt.Sprintf("%s:%d: %s", file, r.line, fmt.Sprintf(format, args...)))
+	}
+	seen := make(map[string]*Rule)
+	for i, r := range list {
+		if r == nil {
+			errs = append(errs, fmt.Sprintf("%s:%d: empty rule", file, doc.Content[0].Content[i].Line))
+			continue
+		}
+		n := 0
+		for _, s := range []string{r.Path, r.Prefix, r.Regexp} {
+			if s != "" {
+				n++
+			}
+		}
+		if n != 1 {
+			errorf(r, "rule must have exactly one of path, prefix, regexp")
+			continue
+		}
+		if r.To == "" {
+			errorf(r, "rule missing to")
+			continue
+		}
+		if r.Status == 0 {
+			r.Status = http.StatusMovedPermanently
+		}
+		if r.Status < 300 || r.Status > 399 {
+			errorf(r, "invalid redirect status %d", r.Status)
+			continue
+		}
+		if (r.Path != "" && !strings.HasPrefix(r.Path, "/")) || (r.Prefix != "" && !strings.HasPrefix(r.Prefix, "/")) {
+			errorf(r, "path must begin with /")
+			continue
+		}
+		if r.Regexp != "" {
+			re, err := regexp.Compile(r.Regexp)
+			if err != nil {
+				errorf(r, "invalid regexp: %v", err)
+				continue
+			}
+			r.re = re
+		}
+		key := r.Host + " " + r.Path + " " + r.Prefix + " " + r.Regexp
+		if old := seen[key]; old != nil {
+			errorf(r, "rule %v conflicts with rule at line %d", r, old.line)
+			continue
+		}
+		seen[key] = r
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+
+	rs := &RuleSet{rules: list}
+	sort.SliceStable(rs.rules, func(i, j int) bool {
+		ri, rj := rs.rules[i], rs.rules[j]
+		if ki, kj := ri.kind(), rj.kind(); ki != kj {
+			return ki < kj
+		}
+		if ri.Prefix != "" && len(ri.Prefix) != len(rj.Prefix) {
+			return len(ri.Prefix) > len(rj.Prefix)
+		}
+		return ri.Host != "" && rj.Host == ""
+	})
+
+	if err := rs.checkLoops(file); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// kind returns the lookup order of the rule's kind:
+// 0 for Path, 1 for Prefix, 2 for Regexp.
+func (r *Rule) kind() int {
+	switch {
+	case r.Path != "":
+		return 0
+	case r.Prefix != "":
+		return 1
+	}
+	return 2
+}
+
+// checkLoops reports an error if following the redirects
+// starting at any exact path or prefix rule leads back to
+// a rule already used along the way.
+// Regexp rules are followed when a chain reaches them,
+// but chains starting at a regexp rule are not checked,
+// since the paths a regexp matches cannot be enumerated.
+func (rs *RuleSet) checkLoops(file string) error {
+	for _, start := range rs.rules {
+		host, p := start.Host, start.Path
+		if start.Prefix != "" {
+			p = start.Prefix
+		} else if p == "" {
+			continue
+		}
+		used := make(map[*Rule]bool)
+		chain := []string{host + p}
+		for {
+			r, target := rs.lookup(host, p)
+			if r == nil {
+				break
+			}
+			chain = append(chain, target)
+			if used[r] {
+				return fmt.Errorf("%s:%d: redirect loop: %s", file, start.line, strings.Join(chain, " -> "))
+			}
+			used[r] = true
+			u, err := url.Parse(target)
+			if err != nil || (u.Host != "" && u.Host != host) {
+				break
+			}
+			p = u.Path
+		}
+	}
+	return nil
+}
+
+// lookup returns the first rule matching host and path p,
+// along with its target.
+func (rs *RuleSet) lookup(host, p string) (*Rule, string) {
+	for _, r := range rs.rules {
+		if target, ok := r.target(host, p); ok {
+			return r, target
+		}
+	}
+	return nil, ""
+}
+
+// Lookup returns the redirect target and status code for a request
+// for the URL path p on the given host, if any rule applies.
+func (rs *RuleSet) Lookup(host, p string) (target string, status int, ok bool) {
+	r, target := rs.lookup(host, p)
+	if r == nil {
+		return "", 0, false
+	}
+	return target, r.Status, true
+}
+
+// Rules is a RuleSet loaded from a file that is reloaded
+// automatically when the file changes.
+type Rules struct {
+	fsys fs.FS
+	file string
+
+	mu      sync.Mutex
+	set     *RuleSet
+	stat    fs.FileInfo // stat for file when set was loaded
+	checked int64       // unix nano, atomically updated
+}
+
+// LoadRules loads the redirect rules from the named YAML file in fsys.
+// See ParseRules for the file format.
+//
+// After loading, the returned Rules checks the file for changes
+// at most once every few seconds and reloads it when it changes.
+// If the new rules fail to load, the error is logged
+// and the previous rules remain in effect.
+func LoadRules(fsys fs.FS, file string) (*Rules, error) {
+	rs := &Rules{fsys: fsys, file: file}
+	if err := rs.Reload(); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// Reload reloads the rules file.
+// If the file cannot be loaded, Reload returns the error
+// and leaves the current rules in effect.
+func (rs *Rules) Reload() error {
+	info, err := fs.Stat(rs.fsys, rs.file)
+	if err != nil {
+		return err
+	}
+	data, err := fs.ReadFile(rs.fsys, rs.file)
+	if err != nil {
+		return err
+	}
+	set, err := ParseRules(rs.file, data)
+	if err != nil {
+		return err
+	}
+	rs.mu.Lock()
+	rs.set = set
+	rs.stat = info
+	rs.mu.Unlock()
+	atomic.StoreInt64(&rs.checked, time.Now().UnixNano())
+	return nil
+}
+
+// current returns the current rule set, reloading it first
+// if the file has changed since it was last loaded.
+func (rs *Rules) current() *RuleSet {
+	// To avoid continuous stats, only check it has been 3s since the last one.
+	now := time.Now().UnixNano()
+	if last := atomic.LoadInt64(&rs.checked); now-last >= 3e9 && atomic.CompareAndSwapInt64(&rs.checked, last, now) {
+		rs.mu.Lock()
+		stat := rs.stat
+		rs.mu.Unlock()
+		info, err := fs.Stat(rs.fsys, rs.file)
+		if err == nil && (!info.ModTime().Equal(stat.ModTime()) || info.Size() != stat.Size()) {
+			if err := rs.Reload(); err != nil {
+				log.Printf("redirect: reloading rules: %v", err)
+			}
+		}
+	}
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return rs.set
+}
+
+// Handler returns an http.Handler that redirects requests
+// matching one of the rules and passes all other requests to next.
+func (rs *Rules) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		target, status, ok := rs.current().Lookup(host, r.URL.Path)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if qs := r.URL.RawQuery; qs != "" {
+			if strings.Contains(target, "?") {
+				target += "&" + qs
+			} else {
+				target += "?" + qs
+			}
+		}
+		http.Redirect(w, r, target, status)
+	})
+}
@@ -0,0 +1,130 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package redirect
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+const testRules = `
+- path: /issues
+  to: https://github.com/goplus/gop/issues
+- prefix: /issues/
+  to: https://github.com/goplus/gop/issues/
+  status: 302
+- prefix: /issues/new/
+  to: https://github.com/goplus/gop/issues/new?template=
+- path: /old
+  to: /new
+- path: /old
+  host: go.dev
+  to: /go.dev/new
+- regexp: ^/doc/articles/([a-z_]+)\.html$
+  to: /doc/$1
+  status: 307
+`
+
+func TestRules(t *testing.T) {
+	var tests = []struct {
+		host, path string
+		status     int
+		target     string
+	}{
+		{"goplus.org", "/issues", 301, "https://github.com/goplus/gop/issues"},
+		{"goplus.org", "/issues/1", 302, "https://github.com/goplus/gop/issues/1"},
+		{"goplus.org", "/issues/new/x", 301, "https://github.com/goplus/gop/issues/new?template=x"},
+		{"goplus.org", "/old", 301, "/new"},
+		{"go.dev", "/old", 301, "/go.dev/new"},
+		{"go.dev:443", "/old?q=1", 301, "/go.dev/new?q=1"},
+		{"goplus.org", "/doc/articles/json_and_go.html", 307, "/doc/json_and_go"},
+		{"goplus.org", "/doc/articles/Bad.html", 200, ""},
+		{"goplus.org", "/new", 200, ""},
+	}
+
+	rs, err := LoadRules(fstest.MapFS{"redirects.yaml": {Data: []byte(testRules)}}, "redirects.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := rs.Handler(http.NotFoundHandler())
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "http://"+tt.host+tt.path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if tt.status == 200 {
+			if w.Code != 404 {
+				t.Errorf("%s%s: got %d, want fall through to next handler", tt.host, tt.path, w.Code)
+			}
+			continue
+		}
+		if w.Code != tt.status || w.Header().Get("Location") != tt.target {
+			t.Errorf("%s%s: got %d %q, want %d %q", tt.host, tt.path, w.Code, w.Header().Get("Location"), tt.status, tt.target)
+		}
+	}
+}
+
+func TestRulesErrors(t *testing.T) {
+	var tests = []struct {
+		rules string
+		err   string
+	}{
+		{"- path: /a\n  prefix: /b/\n  to: /c\n", "exactly one of"},
+		{"- path: /a\n", "missing to"},
+		{"- path: a\n  to: /b\n", "must begin with /"},
+		{"- path: /a\n  to: /b\n  status: 200\n", "invalid redirect status"},
+		{"- regexp: (\n  to: /b\n", "invalid regexp"},
+		{"- path: /a\n  to: /b\n- path: /a\n  to: /c\n", "redirects.yaml:3: rule /a -> /c conflicts with rule at line 1"},
+		{"- path: /a\n  to: /b\n- path: /b\n  to: /a\n", "redirect loop: /a -> /b -> /a"},
+		{"- prefix: /a/\n  to: /a/b/\n", "redirect loop"},
+		{"- path: /a\n  host: x.org\n  to: https://x.org/a\n", "redirect loop"},
+	}
+	for _, tt := range tests {
+		_, err := ParseRules("redirects.yaml", []byte(tt.rules))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("ParseRules(%q) = %v, want error containing %q", tt.rules, err, tt.err)
+		}
+	}
+
+	// Loops through a different host are fine.
+	if _, err := ParseRules("redirects.yaml", []byte("- path: /a\n  to: https://x.org/a\n")); err != nil {
+		t.Errorf("redirect to other host: %v", err)
+	}
+}
+
+func TestRulesReload(t *testing.T) {
+	fsys := fstest.MapFS{"redirects.yaml": {Data: []byte("- path: /a\n  to: /b\n")}}
+	rs, err := LoadRules(fsys, "redirects.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := func(want string) {
+		t.Helper()
+		target, _, _ := rs.current().Lookup("", "/a")
+		if target != want {
+			t.Errorf("after reload: /a -> %q, want %q", target, want)
+		}
+	}
+
+	// A changed file is picked up once the check interval passes.
+	fsys["redirects.yaml"] = &fstest.MapFile{Data: []byte("- path: /a\n  to: /c\n"), ModTime: time.Unix(1, 0)}
+	rs.checked = 0
+	check("/c")
+
+	// A broken file leaves the old rules in place.
+	fsys["redirects.yaml"] = &fstest.MapFile{Data: []byte("- path: /a\n"), ModTime: time.Unix(2, 0)}
+	rs.checked = 0
+	check("/c")
+}
+
+func TestContentRules(t *testing.T) {
+	if _, err := LoadRules(os.DirFS("../../_content"), "redirects.yaml"); err != nil {
+		t.Fatal(err)
+	}
+}
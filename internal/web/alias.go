@@ -0,0 +1,233 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package web
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// An aliasIndex holds the site's current aliases,
+// rebuilt in the background when the site's pages change.
+type aliasIndex struct {
+	mu       sync.Mutex
+	cur      *aliases  // current index; nil until first built
+	checked  time.Time // when cur was last checked against the file system
+	checking bool      // whether a background check is running
+}
+
+// An aliases maps old page URLs, listed in the “aliases” metadata
+// of the pages that replaced them, to those pages' canonical URLs.
+// Since building it requires loading every page, it also indexes
+// the canonical URLs of all pages, for suggesting pages on 404 errors.
+// An aliases is immutable once built.
+type aliases struct {
+	m       map[string]string // pageKey(alias) -> canonical URL
+	suggest *suggestIndex     // canonical URLs of all pages except redirects, for suggestPage
+	errs    []error           // conflicts found while building m
+	sum     [sha256.Size]byte // pageFilesSum of the file system when built
+}
+
+// aliasRefresh is how often the alias index is checked for changed pages.
+const aliasRefresh = time.Minute
+
+// pageKey returns the canonical key for the page file or URL path file,
+// stripping any trailing .html, .md, /index.html, /index.md, or /.
+// All of these forms name the same page.
+func pageKey(file string) string {
+	if strings.HasSuffix(file, "/index.md") {
+		file = strings.TrimSuffix(file, "/index.md")
+	} else if strings.HasSuffix(file, "/index.html") {
+		file = strings.TrimSuffix(file, "/index.html")
+	} else if file == "index.md" || file == "index.html" {
+		file = "."
+	} else if strings.HasSuffix(file, "/") {
+		file = strings.TrimSuffix(file, "/")
+	} else if strings.HasSuffix(file, ".html") {
+		file = strings.TrimSuffix(file, ".html")
+	} else {
+		file = strings.TrimSuffix(file, ".md")
+	}
+	return file
+}
+
+// lookupAlias returns the canonical URL of the page
+// listing relpath among its aliases, if any.
+func (s *Site) lookupAlias(relpath string) (string, bool) {
+	u, ok := s.aliasIndex().m[pageKey(relpath)]
+	return u, ok
+}
+
+// CheckAliases rebuilds the site's alias index and returns the conflicts found:
+// aliases naming pages or files that exist in the file system,
+// and aliases claimed by more than one page.
+// Conflicting aliases are ignored when serving requests.
+//
+// Building the index loads every page in the site, so a server should
+// call CheckAliases before serving requests. Otherwise the first request
+// needing the index builds it. After that, the index is checked for changed
+// pages and rebuilt in the background, never while serving a request.
+func (s *Site) CheckAliases() []error {
+	a := s.buildAliases()
+	x := &s.aliases
+	x.mu.Lock()
+	x.cur = a
+	x.checked = time.Now()
+	x.mu.Unlock()
+	return a.errs
+}
+
+// aliasIndex returns the current alias index, building it if needed.
+// If the index has not been checked against the file system
+// in the last aliasRefresh, aliasIndex starts a background check,
+// but it returns the current index without waiting.
+func (s *Site) aliasIndex() *aliases {
+	x := &s.aliases
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.cur == nil {
+		x.cur = s.buildAliases()
+		x.checked = time.Now()
+		logAliasErrors(x.cur)
+	} else if !x.checking && time.Since(x.checked) >= aliasRefresh {
+		x.checking = true
+		go s.refreshAliases(x.cur)
+	}
+	return x.cur
+}
+
+// refreshAliases rebuilds the alias index if the site's pages
+// have changed since old was built.
+func (s *Site) refreshAliases(old *aliases) {
+	a := old
+	if s.pageFilesSum() != old.sum {
+		a = s.buildAliases()
+		logAliasErrors(a)
+	}
+	x := &s.aliases
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.cur == old {
+		x.cur = a
+	}
+	x.checked = time.Now()
+	x.checking = false
+}
+
+func logAliasErrors(a *aliases) {
+	for _, err := range a.errs {
+		log.Printf("aliases: %v", err)
+	}
+}
+
+// isPageFile reports whether file, found walking the file system,
+// can hold a page.
+func isPageFile(file string, d fs.DirEntry) bool {
+	return !d.IsDir() && (strings.HasSuffix(file, ".md") || strings.HasSuffix(file, ".html"))
+}
+
+// pageFilesSum returns a checksum of the names, sizes, and modification times
+// of the files in the site that can hold pages. It changes when pages
+// are added, removed, or edited, and it is much cheaper than loading them.
+func (s *Site) pageFilesSum() [sha256.Size]byte {
+	h := sha256.New()
+	fs.WalkDir(s.fs, ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil || !isPageFile(file, d) {
+			return nil
+		}
+		sumPageFile(h, file, d)
+		return nil
+	})
+	var sum [sha256.Size]byte
+	h.Sum(sum[:0])
+	return sum
+}
+
+// sumPageFile adds the page file's name, size, and modification time to h.
+func sumPageFile(h io.Writer, file string, d fs.DirEntry) {
+	if info, err := d.Info(); err == nil {
+		fmt.Fprintf(h, "%s\t%d\t%d\n", file, info.Size(), info.ModTime().UnixNano())
+	}
+}
+
+// buildAliases walks the file system, loading every page,
+// and returns the resulting alias index.
+func (s *Site) buildAliases() *aliases {
+	m := make(map[string]string)
+	var urls []string
+	owner := make(map[string]string) // pageKey(alias) -> file listing it
+	var errs []error
+	h := sha256.New()
+	fs.WalkDir(s.fs, ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil || !isPageFile(file, d) {
+			return nil
+		}
+		sumPageFile(h, file, d)
+		p, err := s.openPage(file)
+		if err != nil || p.file != file {
+			return nil
+		}
+		if _, ok := p.page["redirect"]; !ok {
+			urls = append(urls, p.url)
+		}
+		dir := path.Dir(file)
+		for _, alias := range pageAliases(p.page) {
+			if !path.IsAbs(alias) {
+				alias = path.Join("/", dir, alias)
+			}
+			key := pageKey(strings.Trim(path.Clean(alias), "/"))
+			if key == "" {
+				key = "."
+			}
+			if other, ok := owner[key]; ok {
+				errs = append(errs, fmt.Errorf("%s: alias %s already claimed by %s", file, alias, other))
+				delete(m, key)
+				continue
+			}
+			owner[key] = file
+			if q, err := s.openPage(key); err == nil {
+				errs = append(errs, fmt.Errorf("%s: alias %s conflicts with page %s", file, alias, q.file))
+				continue
+			}
+			if _, err := fs.Stat(s.fs, key); err == nil {
+				errs = append(errs, fmt.Errorf("%s: alias %s conflicts with file %s", file, alias, key))
+				continue
+			}
+			m[key], _ = p.page["URL"].(string)
+		}
+		return nil
+	})
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	sort.Strings(urls)
+	a := &aliases{m: m, suggest: newSuggestIndex(urls), errs: errs}
+	h.Sum(a.sum[:0])
+	return a
+}
+
+// pageAliases returns the aliases listed in the page's “aliases” metadata,
+// which may be a single string or a list of strings.
+func pageAliases(p Page) []string {
+	switch v := p["aliases"].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var list []string
+		for _, x := range v {
+			if s, ok := x.(string); ok && s != "" {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package web
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// assetHashLen is the number of hex digits of content hash
+// in a fingerprinted asset URL.
+const assetHashLen = 12
+
+// immutable is the Cache-Control policy for fingerprinted asset URLs.
+// Their content never changes: a new version of the file gets a new URL.
+const immutable = "public, max-age=31536000, immutable"
+
+// An assetHash is the cached content hash of an asset file.
+type assetHash struct {
+	modTime time.Time
+	size    int64
+	hash    string
+}
+
+// asset returns the fingerprinted URL for the named file (relative to dir),
+// such as /lib/godoc/style.0123456789ab.css for /lib/godoc/style.css.
+func (site *siteDir) asset(name string) (string, error) {
+	if strings.HasPrefix(name, "/") {
+		name = path.Clean(name)
+	} else {
+		name = path.Join(site.dir, name)
+	}
+	name = strings.Trim(name, "/")
+	info, err := site.deps.stat(site.fs, name)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("asset %s: is a directory", name)
+	}
+	hash, err := site.assetHash(name, info)
+	if err != nil {
+		return "", err
+	}
+	ext := path.Ext(name)
+	return "/" + strings.TrimSuffix(name, ext) + "." + hash + ext, nil
+}
+
+// assetHash returns the content hash of the named file, whose current stat is info.
+// The hash is recomputed only when the file's size or modification time changes.
+func (s *Site) assetHash(name string, info fs.FileInfo) (string, error) {
+	if c, ok := s.assets.Load(name); ok {
+		a := c.(*assetHash)
+		if a.modTime.Equal(info.ModTime()) && a.size == info.Size() {
+			return a.hash, nil
+		}
+	}
+	data, err := fs.ReadFile(s.fs, name)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	hash := fmt.Sprintf("%x", sum[:])[:assetHashLen]
+	s.assets.Store(name, &assetHash{info.ModTime(), info.Size(), hash})
+	return hash, nil
+}
+
+// splitAsset splits a fingerprinted asset path like lib/godoc/style.0123456789ab.css
+// into the underlying file name (lib/godoc/style.css) and the hash.
+// It reports whether relpath has the form of a fingerprinted path.
+func splitAsset(relpath string) (file, hash string, ok bool) {
+	dir, elem := path.Split(relpath)
+	ext := path.Ext(elem)
+	stem := strings.TrimSuffix(elem, ext)
+	hash = strings.TrimPrefix(path.Ext(stem), ".")
+	if !isAssetHash(hash) {
+		// Maybe a file without an extension, like LICENSE.0123456789ab.
+		if hash = strings.TrimPrefix(ext, "."); !isAssetHash(hash) {
+			return "", "", false
+		}
+		return dir + stem, hash, true
+	}
+	return dir + strings.TrimSuffix(stem, "."+hash) + ext, hash, true
+}
+
+func isAssetHash(s string) bool {
+	if len(s) != assetHashLen {
+		return false
+	}
+	for _, c := range s {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// serveAsset serves the request for relpath if it is a fingerprinted asset URL,
+// reporting whether it did. A URL whose hash matches the file's current content
+// is served with an immutable Cache-Control policy; one with a stale hash,
+// as found in old copies of pages, is still served, but with no policy.
+func (s *Site) serveAsset(w http.ResponseWriter, r *http.Request, relpath string) bool {
+	file, hash, ok := splitAsset(relpath)
+	if !ok {
+		return false
+	}
+	info, err := fs.Stat(s.fs, file)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if cur, err := s.assetHash(file, info); err == nil && cur == hash {
+		w.Header().Set("Cache-Control", immutable)
+	}
+
+	traceOf(r).setClass(ClassFile)
+	if hasPrecompressed(s.fs, file) {
+		if s.servePrecompressed(w, r, file) {
+			return true
+		}
+		addVary(w.Header())
+	}
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path = "/" + file
+	s.fileServer.ServeHTTP(w, r2)
+	return true
+}
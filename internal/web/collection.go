@@ -0,0 +1,244 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package web
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io/fs"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// A collection is a directory of data records, as loaded by Site.loadCollection.
+type collection struct {
+	records []Page
+	err     error
+	deps    *depRecorder // files and directory listing read to load the collection
+}
+
+// Collection returns the records in the collection stored in the directory dir.
+// See the “Data Collections” section of the package doc comment.
+func (site *Site) Collection(dir string) ([]Page, error) {
+	return (&siteDir{site, ".", "", nil}).collection(dir)
+}
+
+// collection returns the records in the collection stored in the directory name
+// (relative to dir), recording the files it depends on in site.deps.
+func (site *siteDir) collection(name string) ([]Page, error) {
+	if !path.IsAbs(name) {
+		name = path.Join(site.dir, name)
+	}
+	dir := strings.Trim(path.Clean(name), "/")
+	if dir == "" {
+		dir = "."
+	}
+
+	var c *collection
+	if v, ok := site.collections.Load(dir); ok && v.(*collection).deps.valid(site.fs) {
+		c = v.(*collection)
+	} else {
+		c = site.loadCollection(dir)
+		site.collections.Store(dir, c)
+	}
+	site.deps.add(c.deps)
+	return c.records, c.err
+}
+
+// loadCollection loads the collection stored in dir.
+func (site *Site) loadCollection(dir string) *collection {
+	c := &collection{deps: newDepRecorder()}
+	if info, err := c.deps.stat(site.fs, dir); err != nil || !info.IsDir() {
+		if err == nil {
+			err = fmt.Errorf("%s: not a directory", dir)
+		}
+		c.err = err
+		return c
+	}
+	// Glob metacharacters in dir would confuse the listing, so reject them.
+	if strings.ContainsAny(dir, `*?[\`) {
+		c.err = fmt.Errorf("%s: invalid collection directory", dir)
+		return c
+	}
+	files, err := c.deps.glob(site.fs, path.Join(dir, "*"))
+	if err != nil {
+		c.err = err
+		return c
+	}
+	c.records = []Page{}
+	for _, file := range files {
+		var decode func([]byte) ([]Page, error)
+		switch path.Ext(file) {
+		default:
+			continue
+		case ".yaml", ".yml", ".json":
+			decode = decodeRecords
+		case ".csv":
+			decode = decodeCSVRecords
+		}
+		if _, err := c.deps.stat(site.fs, file); err != nil {
+			c.err = err
+			return c
+		}
+		data, err := fs.ReadFile(site.fs, file)
+		if err != nil {
+			c.err = err
+			return c
+		}
+		recs, err := decode(data)
+		if err != nil {
+			c.err = fmt.Errorf("%s: %v", file, err)
+			return c
+		}
+		c.records = append(c.records, recs...)
+	}
+	return c
+}
+
+// decodeRecords decodes a YAML or JSON file holding
+// either a single record or a list of records.
+func decodeRecords(data []byte) ([]Page, error) {
+	var d interface{}
+	if err := yaml.Unmarshal(data, &d); err != nil {
+		return nil, err
+	}
+	switch d := d.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		return []Page{d}, nil
+	case []interface{}:
+		var recs []Page
+		for i, x := range d {
+			m, ok := x.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("record %d is %T, not a map", i, x)
+			}
+			recs = append(recs, m)
+		}
+		return recs, nil
+	}
+	return nil, fmt.Errorf("data is %T, not a record or list of records", d)
+}
+
+// decodeCSVRecords decodes a CSV file with a header line naming the
+// record fields, followed by one line per record.
+// Fields that look like integers or floating-point numbers
+// are decoded as int or float64.
+func decodeCSVRecords(data []byte) ([]Page, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.TrimLeadingSpace = true
+	lines, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	header := lines[0]
+	var recs []Page
+	for _, line := range lines[1:] {
+		rec := make(Page)
+		for i, f := range line {
+			var v interface{} = f
+			if n, err := strconv.Atoi(f); err == nil {
+				v = n
+			} else if x, err := strconv.ParseFloat(f, 64); err == nil {
+				v = x
+			}
+			rec[header[i]] = v
+		}
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
+
+// where is the {{where key [op] value pages}} template function.
+func where(key string, args ...interface{}) ([]Page, error) {
+	op := "=="
+	switch len(args) {
+	default:
+		return nil, fmt.Errorf("where: want key, optional operator, value, and pages")
+	case 2:
+		// ok
+	case 3:
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("where: operator %v is %T, not string", args[0], args[0])
+		}
+		op = s
+	}
+	value := args[len(args)-2]
+	pages, ok := args[len(args)-1].([]Page)
+	if !ok {
+		return nil, fmt.Errorf("where: cannot filter %T, only []Page", args[len(args)-1])
+	}
+
+	var match func(x interface{}) bool
+	switch op {
+	default:
+		return nil, fmt.Errorf("where: unknown operator %q", op)
+	case "==", "!=", "<", "<=", ">", ">=":
+		match = func(x interface{}) bool {
+			if x == nil {
+				return op == "!="
+			}
+			c := compareValues(x, whereValue(x, value))
+			switch op {
+			case "==":
+				return c == 0
+			case "!=":
+				return c != 0
+			case "<":
+				return c < 0
+			case "<=":
+				return c <= 0
+			case ">":
+				return c > 0
+			}
+			return c >= 0
+		}
+	case "contains":
+		match = func(x interface{}) bool {
+			if s, ok := x.(string); ok {
+				return strings.Contains(s, fmt.Sprint(value))
+			}
+			if v := reflect.ValueOf(x); v.Kind() == reflect.Slice {
+				for i := 0; i < v.Len(); i++ {
+					if y := v.Index(i).Interface(); y != nil && compareValues(y, whereValue(y, value)) == 0 {
+						return true
+					}
+				}
+			}
+			return false
+		}
+	}
+
+	out := []Page{}
+	for _, p := range pages {
+		if match(p[key]) {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+// whereValue returns the value to compare against the record value x
+// in a where clause: the given value, converted to a time if x is a time,
+// so that “where "date" ">=" "2021-01-01"” works as expected.
+func whereValue(x, value interface{}) interface{} {
+	if _, ok := x.(time.Time); ok {
+		if t, err := toTime(value); err == nil {
+			return t
+		}
+	}
+	return value
+}
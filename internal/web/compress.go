@@ -0,0 +1,249 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package web
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// minCompressSize is the smallest response body, in bytes,
+// that is worth compressing, when the size is known in advance.
+const minCompressSize = 256
+
+// precompressed lists the content encodings that can be served
+// from precompressed sibling files, in order of preference.
+var precompressed = []struct {
+	encoding string
+	ext      string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// acceptsEncoding reports whether the request r's Accept-Encoding header
+// allows a response with the given content encoding.
+func acceptsEncoding(r *http.Request, encoding string) bool {
+	for _, f := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params := f, ""
+		if i := strings.Index(f, ";"); i >= 0 {
+			name, params = f[:i], f[i+1:]
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != encoding && name != "*" {
+			continue
+		}
+		q := 1.0
+		for _, p := range strings.Split(params, ";") {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				var err error
+				if q, err = strconv.ParseFloat(p[len("q="):], 64); err != nil {
+					q = 0
+				}
+			}
+		}
+		if q > 0 {
+			return true
+		}
+		if name == encoding {
+			return false // explicitly refused; ignore *
+		}
+	}
+	return false
+}
+
+// isCompressible reports whether responses with the given content type
+// are worth compressing.
+func isCompressible(ctype string) bool {
+	ctype, _, _ = mime.ParseMediaType(ctype)
+	switch {
+	case strings.HasPrefix(ctype, "text/"),
+		strings.HasSuffix(ctype, "javascript"),
+		strings.HasSuffix(ctype, "json"),
+		strings.HasSuffix(ctype, "xml"):
+		return true
+	}
+	return false
+}
+
+// servePrecompressed serves the file relpath from a precompressed sibling
+// file (relpath.br or relpath.gz) if one exists and the request accepts
+// its encoding. It reports whether it served the request.
+func (s *Site) servePrecompressed(w http.ResponseWriter, r *http.Request, relpath string) bool {
+	for _, pc := range precompressed {
+		if !acceptsEncoding(r, pc.encoding) {
+			continue
+		}
+		f, err := s.fs.Open(relpath + pc.ext)
+		if err != nil {
+			continue
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil || info.IsDir() {
+			continue
+		}
+		content, ok := f.(io.ReadSeeker)
+		if !ok {
+			data, err := io.ReadAll(f)
+			if err != nil {
+				continue
+			}
+			content = bytes.NewReader(data)
+		}
+
+		// The content type comes from the uncompressed file's name:
+		// sniffing the compressed bytes would say application/octet-stream.
+		ctype := mime.TypeByExtension(path.Ext(relpath))
+		if ctype == "" {
+			ctype = "application/octet-stream"
+		}
+		h := w.Header()
+		h.Set("Content-Type", ctype)
+		h.Set("Content-Encoding", pc.encoding)
+		addVary(h)
+		http.ServeContent(w, r, relpath, info.ModTime(), content)
+		return true
+	}
+	return false
+}
+
+// hasPrecompressed reports whether the file relpath has any precompressed siblings.
+func hasPrecompressed(fsys fs.FS, relpath string) bool {
+	for _, pc := range precompressed {
+		if _, err := fs.Stat(fsys, relpath+pc.ext); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// addVary adds Accept-Encoding to the Vary header in h, if it is not already there.
+func addVary(h http.Header) {
+	for _, v := range h.Values("Vary") {
+		for _, f := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(f), "Accept-Encoding") {
+				return
+			}
+		}
+	}
+	h.Add("Vary", "Accept-Encoding")
+}
+
+var gzipWriters sync.Pool // of *gzip.Writer
+
+// A gzipResponseWriter is an http.ResponseWriter that
+// compresses the response body with gzip when appropriate.
+// The decision is made when the response body begins,
+// once the status and content type are known.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	r       *http.Request
+	ok      bool // client accepts gzip
+	code    int  // status passed to WriteHeader, or 0
+	started bool // response header has been written
+	gz      *gzip.Writer
+}
+
+// newGzipResponseWriter returns a gzipResponseWriter writing the response to r to w.
+// The caller must call close when the response is complete.
+func newGzipResponseWriter(w http.ResponseWriter, r *http.Request) *gzipResponseWriter {
+	return &gzipResponseWriter{ResponseWriter: w, r: r, ok: acceptsEncoding(r, "gzip")}
+}
+
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	if w.code == 0 && !w.started {
+		w.code = code
+	}
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	if !w.started {
+		w.start(b)
+	}
+	if w.gz != nil {
+		return w.gz.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// start decides whether to compress the response,
+// whose body begins with b, and writes the response header.
+func (w *gzipResponseWriter) start(b []byte) {
+	w.started = true
+	code := w.code
+	if code == 0 {
+		code = http.StatusOK
+	}
+	h := w.Header()
+	ctype := h.Get("Content-Type")
+	if ctype == "" && len(b) > 0 && h.Get("Content-Encoding") == "" {
+		// Sniff now, as net/http would, since it cannot sniff compressed bytes.
+		ctype = http.DetectContentType(b)
+		h.Set("Content-Type", ctype)
+	}
+	if isCompressible(ctype) && h.Get("Content-Encoding") == "" {
+		addVary(h)
+		small := false
+		if n, err := strconv.Atoi(h.Get("Content-Length")); err == nil && n < minCompressSize {
+			small = true
+		}
+		if w.ok && !small && code >= 200 && code != http.StatusNoContent && code != http.StatusNotModified &&
+			h.Get("Content-Range") == "" && w.r.Method != "HEAD" {
+			h.Del("Content-Length")
+			h.Set("Content-Encoding", "gzip")
+			// The compressed body is a different representation
+			// of the same content: its ETag can only be weak.
+			if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+				h.Set("ETag", "W/"+etag)
+			}
+			gz, _ := gzipWriters.Get().(*gzip.Writer)
+			if gz == nil {
+				gz = gzip.NewWriter(w.ResponseWriter)
+			} else {
+				gz.Reset(w.ResponseWriter)
+			}
+			w.gz = gz
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Flush implements http.Flusher.
+func (w *gzipResponseWriter) Flush() {
+	if !w.started {
+		w.start(nil)
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// close completes the response.
+func (w *gzipResponseWriter) close() {
+	if !w.started {
+		if w.code == 0 {
+			return // nothing written; let net/http finish the response
+		}
+		w.start(nil)
+	}
+	if w.gz != nil {
+		w.gz.Close()
+		gzipWriters.Put(w.gz)
+		w.gz = nil
+	}
+}
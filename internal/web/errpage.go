@@ -0,0 +1,142 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package web
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// errorLayout returns the layout for an error page with the given status
+// served for the URL path urlPath: the status-specific layout, such as “404”,
+// if there is a 404.tmpl in the page's directory or a parent directory,
+// or else the generic “error” layout.
+func (s *Site) errorLayout(urlPath string, status int) string {
+	layout := strconv.Itoa(status)
+	if _, ok := s.findLayout(s.pageDir(urlPath), layout, nil); ok {
+		return layout
+	}
+	return "error"
+}
+
+// A suggestIndex holds the site's page URLs arranged for suggestPage.
+type suggestIndex struct {
+	byKey  map[string]string         // suggestKey(url) -> url
+	byBase map[string][]string       // path.Base(suggestKey(url)) -> urls
+	byDir  map[string][]suggestEntry // path.Dir(suggestKey(url)) -> entries
+}
+
+type suggestEntry struct {
+	key string // suggestKey(url)
+	url string
+}
+
+// maxSuggestCompare bounds the number of candidate pages
+// suggestPage considers for a single request.
+const maxSuggestCompare = 200
+
+// suggestKey returns the form of the URL path u used to compare URLs,
+// ignoring case and the differences between /x, /x/, /x.html, and /x.md.
+func suggestKey(u string) string {
+	return "/" + strings.ToLower(strings.Trim(pageKey(strings.Trim(u, "/")), "/."))
+}
+
+// newSuggestIndex returns the suggestIndex for the sorted page URLs.
+func newSuggestIndex(urls []string) *suggestIndex {
+	x := &suggestIndex{
+		byKey:  make(map[string]string),
+		byBase: make(map[string][]string),
+		byDir:  make(map[string][]suggestEntry),
+	}
+	for _, u := range urls {
+		key := suggestKey(u)
+		if _, ok := x.byKey[key]; !ok {
+			x.byKey[key] = u
+		}
+		if base := path.Base(key); base != "/" {
+			x.byBase[base] = append(x.byBase[base], u)
+		}
+		dir := path.Dir(key)
+		x.byDir[dir] = append(x.byDir[dir], suggestEntry{key, u})
+	}
+	return x
+}
+
+// suggestPage returns the URL of the page most likely meant
+// by a request for the missing URL path urlPath, or "" if no page is
+// close enough. The candidates are the site's page URLs.
+// A page is close if its URL differs from urlPath only in case
+// or in the differences between /x, /x/, /x.html, and /x.md,
+// if it is in the same directory and a short edit away from urlPath,
+// or if its URL ends in the same final path element, as when a page
+// has moved to another directory.
+// Requests for paths in directories holding no pages, as from scanners
+// probing for other software, get only the last kind of suggestion,
+// and at most maxSuggestCompare candidates of each kind are considered.
+func (s *Site) suggestPage(urlPath string) string {
+	x := s.aliasIndex().suggest
+	target := suggestKey(urlPath)
+	if u, ok := x.byKey[target]; ok && u != urlPath {
+		return u
+	}
+
+	best, bestDist := "", 1+len(target)/4
+	consider := func(u string, d int) {
+		if u == urlPath {
+			return
+		}
+		if d < bestDist || d == bestDist && best != "" && len(u) < len(best) {
+			best, bestDist = u, d
+		}
+	}
+	for i, u := range x.byBase[path.Base(target)] {
+		if i == maxSuggestCompare {
+			break
+		}
+		consider(u, 1)
+	}
+	n := 0
+	for _, e := range x.byDir[path.Dir(target)] {
+		if d := len(e.key) - len(target); d > bestDist || -d > bestDist {
+			continue // edit distance is at least the length difference
+		}
+		if n == maxSuggestCompare {
+			break
+		}
+		n++
+		consider(e.url, editDistance(target, e.key))
+	}
+	return best
+}
+
+// fallbackErrorPage is the page served when an error page cannot be rendered,
+// most likely because of a problem with the site templates.
+// It must not depend on anything that might also be broken.
+const fallbackErrorPage = `<!DOCTYPE html>
+<html lang="en">
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>%[1]d %[2]s</title>
+<style>body { font-family: sans-serif; margin: 3em auto; max-width: 40em; padding: 0 1em; }</style>
+<h1>%[1]d %[2]s</h1>
+<p>Sorry, this page could not be displayed. Please try again later.</p>
+<p><a href="/">Home</a></p>
+</html>
+`
+
+// serveFallbackError responds to the request with the static fallbackErrorPage.
+func serveFallbackError(w http.ResponseWriter, status int) {
+	h := w.Header()
+	h.Set("Content-Type", "text/html; charset=utf-8")
+	h.Set("Cache-Control", "no-store")
+	h.Del("ETag")
+	h.Del("Last-Modified")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, fallbackErrorPage, status, html.EscapeString(http.StatusText(status)))
+}
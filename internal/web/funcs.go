@@ -0,0 +1,201 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goplus/website/internal/backport/html/template"
+)
+
+// A PageGroup is a list of pages sharing a metadata value,
+// as returned by the {{groupBy}} template function.
+type PageGroup struct {
+	Key   interface{} // metadata value shared by the pages
+	Pages []Page      // pages with that value, in their original order
+}
+
+// dateLayouts are the string forms of times accepted by toTime.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// toTime converts x, a time.Time or a string holding a date
+// in one of the dateLayouts, to a time.Time.
+func toTime(x interface{}) (time.Time, error) {
+	switch x := x.(type) {
+	case time.Time:
+		return x, nil
+	case string:
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(layout, x); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("cannot parse %q as a date", x)
+	}
+	return time.Time{}, fmt.Errorf("cannot use %T as a date", x)
+}
+
+// dateFn is the {{date layout t}} template function.
+func dateFn(layout string, x interface{}) (string, error) {
+	if x == nil {
+		return "", nil
+	}
+	t, err := toTime(x)
+	if err != nil {
+		return "", err
+	}
+	return t.Format(layout), nil
+}
+
+// defaultFn is the {{default def x}} template function.
+func defaultFn(def, x interface{}) interface{} {
+	if truth, _ := template.IsTrue(x); truth {
+		return x
+	}
+	return def
+}
+
+// dict is the {{dict key value ...}} template function.
+func dict(kv ...interface{}) (map[string]interface{}, error) {
+	if len(kv)%2 != 0 {
+		return nil, fmt.Errorf("dict: odd number of arguments")
+	}
+	m := make(map[string]interface{})
+	for i := 0; i < len(kv); i += 2 {
+		k, ok := kv[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict: key %v is %T, not string", kv[i], kv[i])
+		}
+		m[k] = kv[i+1]
+	}
+	return m, nil
+}
+
+// listFn is the {{list x ...}} template function.
+func listFn(x ...interface{}) []interface{} {
+	return x
+}
+
+// jsonify is the {{jsonify x}} template function.
+func jsonify(x interface{}) (template.JS, error) {
+	js, err := json.Marshal(x)
+	if err != nil {
+		return "", err
+	}
+	return template.JS(js), nil
+}
+
+// sortBy is the {{sortBy key [order] pages}} template function.
+func sortBy(key string, args ...interface{}) ([]Page, error) {
+	desc := false
+	switch len(args) {
+	default:
+		return nil, fmt.Errorf("sortBy: want key, optional order, and pages")
+	case 1:
+		// ok
+	case 2:
+		switch args[0] {
+		case "asc":
+		case "desc":
+			desc = true
+		default:
+			return nil, fmt.Errorf("sortBy: order %v is not asc or desc", args[0])
+		}
+	}
+	pages, ok := args[len(args)-1].([]Page)
+	if !ok {
+		return nil, fmt.Errorf("sortBy: cannot sort %T, only []Page", args[len(args)-1])
+	}
+
+	out := make([]Page, len(pages))
+	copy(out, pages)
+	sort.SliceStable(out, func(i, j int) bool {
+		x, y := out[i][key], out[j][key]
+		// Pages missing the key sort last in either order.
+		if x == nil || y == nil {
+			return x != nil && y == nil
+		}
+		if desc {
+			return compareValues(y, x) < 0
+		}
+		return compareValues(x, y) < 0
+	})
+	return out, nil
+}
+
+// compareValues returns -1, 0, or +1 according to whether x is less than,
+// equal to, or greater than y. Times and numbers compare by value,
+// and anything else by its string form. A time or number sorts before
+// a value of another kind.
+func compareValues(x, y interface{}) int {
+	cmp := func(less, greater bool) int {
+		switch {
+		case less:
+			return -1
+		case greater:
+			return +1
+		}
+		return 0
+	}
+	tx, xok := x.(time.Time)
+	ty, yok := y.(time.Time)
+	if xok || yok {
+		if xok && yok {
+			return cmp(tx.Before(ty), tx.After(ty))
+		}
+		return cmp(xok, yok)
+	}
+	fx, xok := toFloat(x)
+	fy, yok := toFloat(y)
+	if xok || yok {
+		if xok && yok {
+			return cmp(fx < fy, fx > fy)
+		}
+		return cmp(xok, yok)
+	}
+	return strings.Compare(fmt.Sprint(x), fmt.Sprint(y))
+}
+
+// toFloat returns x as a float64, if x is a number.
+func toFloat(x interface{}) (float64, bool) {
+	switch x := x.(type) {
+	case int:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	case uint64:
+		return float64(x), true
+	case float64:
+		return x, true
+	}
+	return 0, false
+}
+
+// groupBy is the {{groupBy key pages}} template function.
+func groupBy(key string, pages []Page) []PageGroup {
+	var groups []PageGroup
+	index := make(map[string]int)
+	for _, p := range pages {
+		v := p[key]
+		k := fmt.Sprintf("%T %v", v, v)
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, PageGroup{Key: v})
+		}
+		groups[i].Pages = append(groups[i].Pages, p)
+	}
+	return groups
+}
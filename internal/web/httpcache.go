@@ -0,0 +1,99 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package web
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// checkNotModified sets the caching headers for a successful response
+// to r with the given body, rendered from the page p:
+// an ETag (a hash of body), Last-Modified (modtime, the latest modification
+// time of the files the page was rendered from, omitted if zero),
+// and Cache-Control (the page's cache-control policy, if any).
+// If the request's conditional headers show that the client's copy is
+// current, checkNotModified responds with 304 (not modified) and returns true.
+func (s *Site) checkNotModified(w http.ResponseWriter, r *http.Request, p Page, body []byte, modtime time.Time) bool {
+	if r.Method != "" && r.Method != "GET" && r.Method != "HEAD" {
+		return false
+	}
+	h := w.Header()
+	sum := sha256.Sum256(body)
+	etag := fmt.Sprintf(`"%x"`, sum[:12])
+	h.Set("ETag", etag)
+	if !modtime.IsZero() {
+		h.Set("Last-Modified", modtime.UTC().Format(http.TimeFormat))
+	}
+	if cc := s.cacheControl(p); cc != "" {
+		h.Set("Cache-Control", cc)
+	}
+
+	// As in RFC 7232 section 6, If-None-Match takes precedence
+	// over If-Modified-Since.
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if !etagMatch(inm, etag) {
+			return false
+		}
+	} else if ims := r.Header.Get("If-Modified-Since"); ims != "" && !modtime.IsZero() {
+		t, err := http.ParseTime(ims)
+		if err != nil || modtime.Truncate(time.Second).After(t) {
+			return false
+		}
+	} else {
+		return false
+	}
+
+	delete(h, "Content-Type")
+	delete(h, "Content-Length")
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatch reports whether the If-None-Match header value list
+// matches etag, using the weak comparison function.
+func etagMatch(list, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, x := range strings.Split(list, ",") {
+		x = strings.TrimSpace(x)
+		if x == "*" || strings.TrimPrefix(x, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheControl returns the Cache-Control policy for the page p.
+// The policy is set by the “cache-control” key in the page's metadata.
+// Pages without a policy of their own use the policy set in
+// the index page of the nearest enclosing directory that has one.
+func (s *Site) cacheControl(p Page) string {
+	if cc, ok := p["cache-control"].(string); ok {
+		return cc
+	}
+	url, _ := p["URL"].(string)
+	dir := strings.Trim(url, "/")
+	if !strings.HasSuffix(url, "/") {
+		dir = strings.Trim(path.Dir(url), "/")
+	}
+	if dir == "" {
+		dir = "."
+	}
+	for {
+		if ip, err := s.openPage(dir + "/"); err == nil && ip.page["URL"] != url {
+			if cc, ok := ip.page["cache-control"].(string); ok {
+				return cc
+			}
+		}
+		if dir == "." {
+			return ""
+		}
+		dir = path.Dir(dir)
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package web
+
+import (
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// langCookie is the name of the cookie recording a reader's language choice.
+const langCookie = "lang"
+
+// SetLanguages sets the languages the site's content is written in.
+// The first language is the default: pages written in it have no
+// language marker in their file names or URLs.
+// See the package doc comment for details.
+// SetLanguages must not be called concurrently with serving requests.
+func (s *Site) SetLanguages(langs ...string) {
+	s.langs = append([]string(nil), langs...)
+}
+
+// defaultLang returns the site's default language, or "" if it has none.
+func (s *Site) defaultLang() string {
+	if len(s.langs) == 0 {
+		return ""
+	}
+	return s.langs[0]
+}
+
+// isTranslation reports whether lang is one of the site's languages
+// other than the default language.
+func (s *Site) isTranslation(lang string) bool {
+	for i, l := range s.langs {
+		if i > 0 && l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// splitLang splits a relative path like zh/doc/x into
+// a translation language (zh) and the rest of the path (doc/x).
+// If relpath does not begin with a translation language,
+// splitLang returns "", relpath.
+func (s *Site) splitLang(relpath string) (lang, rest string) {
+	elem, rest := relpath, "."
+	if i := strings.Index(relpath, "/"); i >= 0 {
+		elem, rest = relpath[:i], relpath[i+1:]
+		if rest == "" {
+			rest = "."
+		}
+	}
+	if !s.isTranslation(elem) {
+		return "", relpath
+	}
+	return elem, rest
+}
+
+// pageDir returns the directory for interpreting relative paths
+// in the page with the given URL: the directory of the URL, without
+// any translation language prefix, so that a translation uses the same
+// files and layouts as its default-language version.
+func (s *Site) pageDir(url string) string {
+	dir := strings.Trim(path.Dir(url), "/")
+	if dir == "" {
+		dir = "."
+	}
+	if len(s.langs) > 0 {
+		_, dir = s.splitLang(dir)
+	}
+	return dir
+}
+
+// pageFiles returns the files that may hold the page with the given key,
+// in order of preference.
+func (s *Site) pageFiles(key string) []string {
+	files := []string{key + ".md", key + ".html", path.Join(key, "index.md"), path.Join(key, "index.html")}
+	if lang, rest := s.splitLang(key); lang != "" {
+		// The translation of x.md into zh can also be x.zh.md.
+		if rest != "." {
+			files = append(files, rest+"."+lang+".md", rest+"."+lang+".html")
+		}
+		files = append(files, path.Join(rest, "index."+lang+".md"), path.Join(rest, "index."+lang+".html"))
+	}
+	return files
+}
+
+// fileLang returns the language of the page in file,
+// along with the name the file would have in the default language's
+// file layout, without a language marker like the .zh in x.zh.md.
+// Files in a translation language's subtree, like zh/x.md,
+// keep their names.
+func (s *Site) fileLang(file string) (lang, plain string) {
+	if len(s.langs) == 0 {
+		return "", file
+	}
+	dir, name := path.Split(file)
+	ext := path.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	if l := strings.TrimPrefix(path.Ext(stem), "."); s.isTranslation(l) {
+		return l, dir + strings.TrimSuffix(stem, "."+l) + ext
+	}
+	if l, _ := s.splitLang(file); l != "" {
+		return l, file
+	}
+	return s.defaultLang(), file
+}
+
+// preferredLang returns the site language preferred by the request r:
+// the one named by its lang cookie, if any, or else the best match for its
+// Accept-Language header, or else the default language.
+func (s *Site) preferredLang(r *http.Request) string {
+	if c, err := r.Cookie(langCookie); err == nil && (c.Value == s.defaultLang() || s.isTranslation(c.Value)) {
+		return c.Value
+	}
+
+	type choice struct {
+		lang string
+		q    float64
+	}
+	var choices []choice
+	for _, f := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, params := f, ""
+		if i := strings.Index(f, ";"); i >= 0 {
+			tag, params = f[:i], f[i+1:]
+		}
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		q := 1.0
+		if p := strings.TrimSpace(params); strings.HasPrefix(p, "q=") {
+			var err error
+			if q, err = strconv.ParseFloat(p[len("q="):], 64); err != nil {
+				q = 0
+			}
+		}
+		for _, l := range s.langs {
+			// zh matches zh, zh-CN, zh-Hans, and so on.
+			if q > 0 && (tag == l || strings.HasPrefix(tag, l+"-")) {
+				choices = append(choices, choice{l, q})
+				break
+			}
+		}
+	}
+	if len(choices) == 0 {
+		return s.defaultLang()
+	}
+	sort.SliceStable(choices, func(i, j int) bool { return choices[i].q > choices[j].q })
+	return choices[0].lang
+}
+
+// serveLangChoice handles a request carrying a ?lang=xx query parameter,
+// recording the reader's choice of language xx in a cookie
+// and redirecting to the URL without the parameter
+// (and without any language prefix), where preferredLang will
+// then pick the chosen language.
+// It reports whether it served the request.
+func (s *Site) serveLangChoice(w http.ResponseWriter, r *http.Request, relpath string) bool {
+	if len(s.langs) == 0 || (r.Method != "GET" && r.Method != "HEAD") {
+		return false
+	}
+	q := r.URL.Query()
+	lang := q.Get(langCookie)
+	if lang != s.defaultLang() && !s.isTranslation(lang) {
+		return false
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     langCookie,
+		Value:    lang,
+		Path:     "/",
+		MaxAge:   365 * 24 * 60 * 60,
+		SameSite: http.SameSiteLaxMode,
+	})
+	q.Del(langCookie)
+	u := *r.URL
+	u.RawQuery = q.Encode()
+	if l, rest := s.splitLang(relpath); l != "" {
+		u.Path = path.Join("/", rest)
+		if strings.HasSuffix(r.URL.Path, "/") && u.Path != "/" {
+			u.Path += "/"
+		}
+	}
+	http.Redirect(w, r, u.String(), http.StatusFound)
+	return true
+}
+
+// negotiateLang redirects a request for the default-language page p
+// to its translation into the request's preferred language, if one exists.
+// It reports whether it served the request.
+func (s *Site) negotiateLang(w http.ResponseWriter, r *http.Request, relpath string, p *pageFile) bool {
+	if len(s.langs) == 0 || p.page["Lang"] != s.defaultLang() {
+		return false
+	}
+	w.Header().Add("Vary", "Accept-Language, Cookie")
+	lang := s.preferredLang(r)
+	if lang == s.defaultLang() {
+		return false
+	}
+	tp, err := s.openPage(path.Join(lang, pageKey(relpath)))
+	if err != nil {
+		return false
+	}
+	u := *r.URL
+	u.Path = tp.url
+	http.Redirect(w, r, u.String(), http.StatusFound)
+	return true
+}
+
+// serveLangFallback redirects a request for a missing page or file
+// in a translation language's URL space, like /zh/doc/x,
+// to the default-language version, like /doc/x, if that exists.
+// It reports whether it served the request.
+func (s *Site) serveLangFallback(w http.ResponseWriter, r *http.Request, relpath string) bool {
+	lang, rest := s.splitLang(relpath)
+	if lang == "" {
+		return false
+	}
+	u := *r.URL
+	if p, err := s.openPage(rest); err == nil {
+		u.Path = p.url
+	} else if _, err := fs.Stat(s.fs, rest); err == nil {
+		u.Path = path.Join("/", rest)
+		if strings.HasSuffix(r.URL.Path, "/") && u.Path != "/" {
+			u.Path += "/"
+		}
+	} else {
+		return false
+	}
+	http.Redirect(w, r, u.String(), http.StatusFound)
+	return true
+}
+
+// A Translation describes one language version of a page.
+type Translation struct {
+	Lang    string // language of this version, such as "en" or "zh"
+	URL     string // URL of this version
+	Link    string // URL of this version that also records it as the reader's choice
+	Current bool   // whether this is the version being rendered
+}
+
+// translations returns the available language versions of the page p,
+// in the order given to SetLanguages.
+func (site *siteDir) translations(p Page) []Translation {
+	if len(site.langs) == 0 {
+		return nil
+	}
+	url, _ := p["URL"].(string)
+	_, rest := site.splitLang(strings.Trim(url, "/"))
+	if rest == "" {
+		rest = "."
+	}
+	key := pageKey(rest)
+	var list []Translation
+	for _, lang := range site.langs {
+		k := key
+		if lang != site.defaultLang() {
+			k = path.Join(lang, key)
+		}
+		tp, err := site.openPage(k)
+		if err != nil || tp.page["Lang"] != lang {
+			continue
+		}
+		list = append(list, Translation{
+			Lang:    lang,
+			URL:     tp.url,
+			Link:    tp.url + "?" + langCookie + "=" + lang,
+			Current: tp.url == url,
+		})
+	}
+	return list
+}
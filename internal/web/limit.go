@@ -0,0 +1,137 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/goplus/website/internal/backport/html/template"
+)
+
+// Default limits on rendering a single page.
+const (
+	defaultRenderTimeout = 10 * time.Second
+	defaultRenderSize    = 16 << 20
+)
+
+// SetRenderLimits sets the limits on rendering a single page:
+// the maximum total time spent executing the page's templates
+// and the maximum size in bytes of its rendered content and of its framed HTML.
+// A zero timeout or size disables that limit.
+// SetRenderLimits must not be called concurrently with serving requests.
+func (s *Site) SetRenderLimits(timeout time.Duration, size int64) {
+	s.renderTimeout = timeout
+	s.renderSize = size
+}
+
+// A renderLimit holds the limits for rendering one page.
+type renderLimit struct {
+	timeout  time.Duration
+	deadline time.Time // zero if there is no time limit
+	size     int64     // 0 if there is no size limit
+	trace    *Trace    // trace of the request being served, or nil
+}
+
+// newRenderLimit returns the limits for a page rendering
+// for the request r starting now.
+func (s *Site) newRenderLimit(r *http.Request) *renderLimit {
+	l := &renderLimit{timeout: s.renderTimeout, size: s.renderSize, trace: traceOf(r)}
+	if l.timeout > 0 {
+		l.deadline = time.Now().Add(l.timeout)
+	}
+	return l
+}
+
+// check returns an error if the time limit has passed.
+func (l *renderLimit) check() error {
+	if !l.deadline.IsZero() && time.Now().After(l.deadline) {
+		return l.timeoutError()
+	}
+	return nil
+}
+
+// funcs returns a copy of the template functions m in which each function
+// first checks the time limit, failing the execution once it has passed.
+func (l *renderLimit) funcs(m template.FuncMap) template.FuncMap {
+	if l.deadline.IsZero() {
+		return m
+	}
+	out := make(template.FuncMap, len(m))
+	for name, fn := range m {
+		v := reflect.ValueOf(fn)
+		if v.Kind() != reflect.Func {
+			out[name] = fn // let the template package report it
+			continue
+		}
+		out[name] = reflect.MakeFunc(v.Type(), func(args []reflect.Value) []reflect.Value {
+			if err := l.check(); err != nil {
+				// The template package turns the panic into an execution error.
+				panic(err)
+			}
+			if v.Type().IsVariadic() {
+				return v.CallSlice(args)
+			}
+			return v.Call(args)
+		}).Interface()
+	}
+	return out
+}
+
+// execute executes t with the given data, subject to the limits l,
+// and returns the output.
+//
+// The template package has no way to interrupt an execution,
+// so if the time limit passes, execute returns without waiting for it,
+// recording the abandoned execution in l's trace.
+// That execution keeps running until its next write or call of
+// a template function wrapped by l.funcs, which fail once the limit has passed.
+// A loop that does neither runs to completion in the background.
+func (l *renderLimit) execute(t *template.Template, data interface{}) ([]byte, error) {
+	w := &limitWriter{limit: l}
+	if l.deadline.IsZero() {
+		err := t.Execute(w, data)
+		return w.buf.Bytes(), err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- t.Execute(w, data)
+	}()
+	timer := time.NewTimer(time.Until(l.deadline))
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return w.buf.Bytes(), err
+	case <-timer.C:
+		l.trace.abandon()
+		return nil, l.timeoutError()
+	}
+}
+
+func (l *renderLimit) timeoutError() error {
+	return fmt.Errorf("rendering took longer than %v", l.timeout)
+}
+
+// A limitWriter is a buffer that rejects writes
+// once its renderLimit is exceeded.
+type limitWriter struct {
+	limit *renderLimit
+	buf   bytes.Buffer
+}
+
+func (w *limitWriter) Write(b []byte) (int, error) {
+	l := w.limit
+	if err := l.check(); err != nil {
+		return 0, err
+	}
+	if l.size > 0 && int64(w.buf.Len()+len(b)) > l.size {
+		return 0, fmt.Errorf("rendering produced more than %d bytes", l.size)
+	}
+	return w.buf.Write(b)
+}
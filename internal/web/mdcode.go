@@ -0,0 +1,237 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/goplus/website/internal/texthtml"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// mdCodeRenderer renders fenced code blocks.
+// Blocks in Go and Go+ are formatted by texthtml, like {{code}},
+// according to the attributes in their info strings.
+// See the “Markdown Extensions” section of the package doc comment.
+type mdCodeRenderer struct{}
+
+func (r mdCodeRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, r.renderFencedCodeBlock)
+}
+
+// A codeAttrs holds the attributes of a fenced Go or Go+ code block.
+type codeAttrs struct {
+	line  int         // first line number, or 0 for no line numbers
+	lines []lineRange // line ranges to highlight
+	play  bool        // runnable in the playground
+}
+
+// goplusPlayground is the Go+ playground, which runs the runnable Go+ blocks.
+// Runnable Go blocks use the playground backend of the site itself.
+const goplusPlayground = "https://play.goplus.org"
+
+// A lineRange is an inclusive range of 1-based line numbers.
+type lineRange struct {
+	start, end int
+}
+
+// parseCodeAttrs parses the attributes that follow the language in
+// the info string of a fenced code block, such as “linenos hl_lines=3-5”.
+func parseCodeAttrs(attrs []string) (codeAttrs, error) {
+	var a codeAttrs
+	for _, attr := range attrs {
+		key, val := attr, ""
+		if i := strings.Index(attr, "="); i >= 0 {
+			key, val = attr[:i], strings.Trim(attr[i+1:], `"'`)
+		}
+		switch key {
+		case "linenos":
+			a.line = 1
+			if val != "" {
+				n, err := strconv.Atoi(val)
+				if err != nil || n < 1 {
+					return a, fmt.Errorf("invalid linenos=%s", val)
+				}
+				a.line = n
+			}
+		case "hl_lines":
+			for _, f := range strings.Split(val, ",") {
+				lo, hi := f, f
+				if i := strings.Index(f, "-"); i >= 0 {
+					lo, hi = f[:i], f[i+1:]
+				}
+				start, err1 := strconv.Atoi(lo)
+				end, err2 := strconv.Atoi(hi)
+				if err1 != nil || err2 != nil || start < 1 || end < start {
+					return a, fmt.Errorf("invalid hl_lines=%s", val)
+				}
+				a.lines = append(a.lines, lineRange{start, end})
+			}
+		case "play":
+			if val != "" {
+				return a, fmt.Errorf("invalid %s", attr)
+			}
+			a.play = true
+		default:
+			return a, fmt.Errorf("unknown code block attribute %q", attr)
+		}
+	}
+	return a, nil
+}
+
+func (r mdCodeRenderer) renderFencedCodeBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*ast.FencedCodeBlock)
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	var info []string
+	if n.Info != nil {
+		info = strings.Fields(string(n.Info.Segment.Value(source)))
+	}
+	var buf bytes.Buffer
+	for i := 0; i < n.Lines().Len(); i++ {
+		line := n.Lines().At(i)
+		buf.Write(line.Value(source))
+	}
+	text := buf.Bytes()
+
+	if len(info) == 0 || info[0] != "go" && info[0] != "gop" {
+		// Other languages: as goldmark would render them.
+		writePlainCode(w, info, text)
+		return ast.WalkSkipChildren, nil
+	}
+
+	lang := info[0]
+	a, err := parseCodeAttrs(info[1:])
+	if err != nil {
+		// A mistake in the attributes should not break the whole page.
+		// Site.CheckPages reports it with the file and line.
+		log.Printf("```%s: %v", strings.Join(info, " "), err)
+		writePlainCode(w, info, text)
+		return ast.WalkSkipChildren, nil
+	}
+
+	if a.play {
+		// The runnable example widget also used in package documentation;
+		// see setupInlinePlayground in godocs.js.
+		// Go+ blocks run in the Go+ playground, named by data-playground.
+		fmt.Fprintf(w, "<div class=\"play language-%s\"", lang)
+		if lang == "gop" {
+			fmt.Fprintf(w, " data-playground=\"%s\"", goplusPlayground)
+		}
+		w.WriteString(">\n")
+		fmt.Fprintf(w, "<div class=\"input\"><textarea class=\"code\" spellcheck=\"false\">%s</textarea></div>\n", html.EscapeString(string(text)))
+		w.WriteString("<div class=\"output\"><pre></pre></div>\n")
+		w.WriteString("<div class=\"buttons\">\n")
+		w.WriteString("<button class=\"Button Button--primary run\" title=\"Run this code [shift-enter]\">Run</button>\n")
+		w.WriteString("<button class=\"Button fmt\" title=\"Format this code\">Format</button>\n")
+		w.WriteString("</div>\n")
+		w.WriteString("</div>\n")
+		return ast.WalkSkipChildren, nil
+	}
+
+	cfg := texthtml.Config{
+		GoComments: true,
+		Line:       a.line,
+	}
+	if len(a.lines) > 0 {
+		cfg.Selection = lineSpans(text, a.lines)
+	}
+	// Drop the final newline so that the line numbers stop at the last line.
+	text = bytes.TrimSuffix(text, []byte("\n"))
+	fmt.Fprintf(w, "<div class=\"code language-%s\"><pre>", lang)
+	w.Write(bytes.TrimSuffix(texthtml.Format(text, cfg), []byte("\n")))
+	w.WriteString("\n</pre></div>\n")
+	return ast.WalkSkipChildren, nil
+}
+
+// writePlainCode writes the code block text with the given info string
+// as goldmark would: escaped in a <pre><code> block
+// with a class naming the language.
+func writePlainCode(w util.BufWriter, info []string, text []byte) {
+	w.WriteString("<pre><code")
+	if len(info) > 0 {
+		w.WriteString(` class="language-`)
+		w.Write(util.EscapeHTML([]byte(info[0])))
+		w.WriteString(`"`)
+	}
+	w.WriteString(">")
+	w.Write(util.EscapeHTML(text))
+	w.WriteString("</code></pre>\n")
+}
+
+// checkCodeBlocks checks the attributes of the fenced Go and Go+
+// code blocks in the Markdown file data, returning an error for each
+// invalid one, prefixed by the file name and line number.
+func checkCodeBlocks(file string, data []byte) []error {
+	var errs []error
+	fence := "" // fence of the code block being skipped, if any
+	for i, line := range strings.Split(string(data), "\n") {
+		t := strings.TrimLeft(line, " ")
+		if len(line)-len(t) > 3 {
+			continue // indented code, not a fence
+		}
+		if fence != "" {
+			if strings.HasPrefix(t, fence) && strings.Trim(t, fence[:1]+" \t") == "" {
+				fence = ""
+			}
+			continue
+		}
+		n := len(t) - len(strings.TrimLeft(t, "`"))
+		if n < 3 {
+			n = len(t) - len(strings.TrimLeft(t, "~"))
+		}
+		if n < 3 {
+			continue
+		}
+		fence = t[:n]
+		info := strings.Fields(t[n:])
+		if len(info) == 0 || info[0] != "go" && info[0] != "gop" {
+			continue
+		}
+		if _, err := parseCodeAttrs(info[1:]); err != nil {
+			errs = append(errs, fmt.Errorf("%s:%d: %s%s: %v", file, i+1, fence, strings.Join(info, " "), err))
+		}
+	}
+	return errs
+}
+
+// lineSpans returns the selection of text covering the lines in the given ranges.
+// Each line is a separate span, not including its newline,
+// so that line numbers added by texthtml are not highlighted.
+// Ranges extending past the end of text are cut off there.
+func lineSpans(text []byte, lines []lineRange) texthtml.Selection {
+	hl := func(l int) bool {
+		for _, r := range lines {
+			if r.start <= l && l <= r.end {
+				return true
+			}
+		}
+		return false
+	}
+	var spans []texthtml.Span
+	start := 0
+	for l := 1; start < len(text); l++ {
+		end := bytes.IndexByte(text[start:], '\n')
+		if end < 0 {
+			end = len(text)
+		} else {
+			end += start
+		}
+		if hl(l) {
+			spans = append(spans, texthtml.Span{Start: start, End: end})
+		}
+		start = end + 1
+	}
+	return texthtml.Spans(spans...)
+}
@@ -0,0 +1,251 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package web
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// This file implements the Markdown extensions for admonitions and tabs.
+// See the “Markdown Extensions” section of the package doc comment.
+
+var (
+	kindAdmonition = ast.NewNodeKind("Admonition")
+	kindTabSet     = ast.NewNodeKind("TabSet")
+	kindTab        = ast.NewNodeKind("Tab")
+)
+
+// An admonition is a callout box, written as
+//
+//	!!! kind "Title"
+//	    Indented content.
+type admonition struct {
+	ast.BaseBlock
+	kind  string // "note", "warning", and so on
+	title string // title to display, or "" for none
+}
+
+func (n *admonition) Kind() ast.NodeKind { return kindAdmonition }
+
+func (n *admonition) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Kind": n.kind, "Title": n.title}, nil)
+}
+
+// A tab is one panel in a set of tabs, written as
+//
+//	=== "Title"
+//	    Indented content.
+//
+// The mdTabSets transformer groups consecutive tabs into a tabSet.
+type tab struct {
+	ast.BaseBlock
+	title string
+}
+
+func (n *tab) Kind() ast.NodeKind { return kindTab }
+
+func (n *tab) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Title": n.title}, nil)
+}
+
+// A tabSet is a sequence of tabs, only one of which is shown at a time.
+type tabSet struct {
+	ast.BaseBlock
+}
+
+func (n *tabSet) Kind() ast.NodeKind { return kindTabSet }
+
+func (n *tabSet) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+var (
+	admonitionRE = regexp.MustCompile(`^!!!\s+([A-Za-z][\w-]*)(\s+"([^"]*)")?\s*$`)
+	tabRE        = regexp.MustCompile(`^===\s+"([^"]*)"\s*$`)
+)
+
+// An indentParser parses a block introduced by a header line
+// and continued by the following lines indented by four spaces,
+// like a list item. The content lines are parsed as Markdown blocks.
+type indentParser struct {
+	trigger byte
+	open    func(header string) ast.Node // returns nil if header does not begin a block
+}
+
+func (b *indentParser) Trigger() []byte {
+	return []byte{b.trigger}
+}
+
+func (b *indentParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, _ := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, parser.NoChildren
+	}
+	header := strings.TrimRight(string(line[pos:]), "\r\n")
+	node := b.open(header)
+	if node == nil {
+		return nil, parser.NoChildren
+	}
+	n := len(line)
+	if line[n-1] == '\n' {
+		n--
+	}
+	reader.Advance(n)
+	return node, parser.HasChildren
+}
+
+func (b *indentParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	line, _ := reader.PeekLine()
+	if util.IsBlank(line) {
+		reader.Advance(len(line) - 1)
+		return parser.Continue | parser.HasChildren
+	}
+	if indent, _ := util.IndentWidth(line, reader.LineOffset()); indent < 4 {
+		return parser.Close
+	}
+	pos, padding := util.IndentPosition(line, reader.LineOffset(), 4)
+	reader.AdvanceAndSetPadding(pos, padding)
+	return parser.Continue | parser.HasChildren
+}
+
+func (b *indentParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+func (b *indentParser) CanInterruptParagraph() bool { return false }
+
+func (b *indentParser) CanAcceptIndentedLine() bool { return false }
+
+// openAdmonition returns the admonition begun by header, if any.
+func openAdmonition(header string) ast.Node {
+	m := admonitionRE.FindStringSubmatch(header)
+	if m == nil {
+		return nil
+	}
+	n := &admonition{kind: strings.ToLower(m[1])}
+	if m[2] != "" {
+		n.title = m[3]
+	} else {
+		n.title = strings.ToUpper(n.kind[:1]) + n.kind[1:]
+	}
+	return n
+}
+
+// openTab returns the tab begun by header, if any.
+func openTab(header string) ast.Node {
+	m := tabRE.FindStringSubmatch(header)
+	if m == nil {
+		return nil
+	}
+	return &tab{title: m[1]}
+}
+
+// mdTabSets walks doc, grouping each run of consecutive tabs into a tabSet.
+func mdTabSets(doc *ast.Document, _ text.Reader, _ parser.Context) {
+	mdTabSetsWalk(doc)
+}
+
+func mdTabSetsWalk(n ast.Node) {
+	var set *tabSet
+	for child := n.FirstChild(); child != nil; {
+		next := child.NextSibling()
+		if _, ok := child.(*tab); ok {
+			if set == nil {
+				set = &tabSet{}
+				n.InsertBefore(n, child, set)
+			}
+			n.RemoveChild(n, child)
+			set.AppendChild(set, child)
+		} else {
+			set = nil
+		}
+		mdTabSetsWalk(child)
+		child = next
+	}
+}
+
+// mdExtensions is the goldmark extension adding admonitions and tabs
+// and formatting fenced Go and Go+ code blocks.
+type mdExtensions struct{}
+
+func (mdExtensions) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(
+			util.Prioritized(&indentParser{'!', openAdmonition}, 750),
+			util.Prioritized(&indentParser{'=', openTab}, 750),
+		),
+		parser.WithASTTransformers(util.Prioritized(mdTransformFunc(mdTabSets), 2)),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(mdExtRenderer{}, 500),
+		util.Prioritized(mdCodeRenderer{}, 500),
+	))
+}
+
+// mdExtRenderer renders admonitions and tabs as HTML.
+type mdExtRenderer struct{}
+
+func (r mdExtRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindAdmonition, r.renderAdmonition)
+	reg.Register(kindTabSet, r.renderTabSet)
+	reg.Register(kindTab, r.renderTab)
+}
+
+func (r mdExtRenderer) renderAdmonition(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*admonition)
+	if !entering {
+		w.WriteString("</div>\n")
+		return ast.WalkContinue, nil
+	}
+	w.WriteString(`<div class="Admonition Admonition--`)
+	w.Write(util.EscapeHTML([]byte(n.kind)))
+	w.WriteString("\">\n")
+	if n.title != "" {
+		w.WriteString(`<p class="Admonition-title">`)
+		w.Write(util.EscapeHTML([]byte(n.title)))
+		w.WriteString("</p>\n")
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r mdExtRenderer) renderTabSet(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		w.WriteString("</div>\n")
+		return ast.WalkContinue, nil
+	}
+	w.WriteString(`<div class="TabSection js-tabs">` + "\n")
+	w.WriteString(`<div class="TabSection-tabList" role="tablist">` + "\n")
+	for c := node.FirstChild(); c != nil; c = c.NextSibling() {
+		selected, tabindex := "true", "0"
+		if c != node.FirstChild() {
+			selected, tabindex = "false", "-1"
+		}
+		w.WriteString(`<button role="tab" aria-selected="` + selected + `" tabindex="` + tabindex + `" class="TabSection-tab">`)
+		w.Write(util.EscapeHTML([]byte(c.(*tab).title)))
+		w.WriteString("</button>\n")
+	}
+	w.WriteString("</div>\n")
+	return ast.WalkContinue, nil
+}
+
+func (r mdExtRenderer) renderTab(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		w.WriteString("</div>\n")
+		return ast.WalkContinue, nil
+	}
+	w.WriteString(`<div role="tabpanel" class="TabSection-tabPanel"`)
+	if node.PreviousSibling() != nil {
+		w.WriteString(" hidden")
+	}
+	w.WriteString(">\n")
+	return ast.WalkContinue, nil
+}
@@ -0,0 +1,262 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package web
+
+import (
+	"container/list"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultOutputCacheSize is the default bound on the
+// total size of the rendered pages in a Site's output cache.
+const defaultOutputCacheSize = 32 << 20
+
+// A dep is a single input to a page rendering:
+// either a file (or the absence of one) or the result of a glob.
+type dep struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+	matches string // for globs, newline-separated matches
+}
+
+// A depRecorder records the inputs read during a page rendering.
+// A nil *depRecorder records nothing.
+type depRecorder struct {
+	mu         sync.Mutex
+	files      map[string]dep // file name -> state when read
+	globs      map[string]dep // glob pattern -> matches when run
+	uncachable bool           // rendering depended on more than the URL
+}
+
+func newDepRecorder() *depRecorder {
+	return &depRecorder{
+		files: make(map[string]dep),
+		globs: make(map[string]dep),
+	}
+}
+
+// stat stats the named file in fsys, recording its state.
+func (d *depRecorder) stat(fsys fs.FS, name string) (fs.FileInfo, error) {
+	info, err := fs.Stat(fsys, name)
+	if d != nil {
+		d.mu.Lock()
+		d.files[name] = statDep(info, err)
+		d.mu.Unlock()
+	}
+	return info, err
+}
+
+// glob runs fs.Glob on fsys, recording its result.
+func (d *depRecorder) glob(fsys fs.FS, pattern string) ([]string, error) {
+	matches, err := fs.Glob(fsys, pattern)
+	if d != nil && err == nil {
+		d.mu.Lock()
+		d.globs[pattern] = dep{matches: strings.Join(matches, "\n")}
+		d.mu.Unlock()
+	}
+	return matches, err
+}
+
+// loaded records the state of the page file pf as it was when the page was loaded,
+// so that a page loaded from a stale copy of the file does not stay in the cache.
+func (d *depRecorder) loaded(pf *pageFile) {
+	if d != nil {
+		d.mu.Lock()
+		d.files[pf.file] = statDep(pf.stat, nil)
+		d.mu.Unlock()
+	}
+}
+
+// add records the inputs recorded in e as inputs recorded in d.
+func (d *depRecorder) add(e *depRecorder) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for name, dep := range e.files {
+		d.files[name] = dep
+	}
+	for pattern, dep := range e.globs {
+		d.globs[pattern] = dep
+	}
+}
+
+// noCache marks the rendering as depending on more than
+// its recorded inputs, so that it must not be cached.
+func (d *depRecorder) noCache() {
+	if d != nil {
+		d.mu.Lock()
+		d.uncachable = true
+		d.mu.Unlock()
+	}
+}
+
+func statDep(info fs.FileInfo, err error) dep {
+	if err != nil {
+		return dep{}
+	}
+	return dep{exists: true, modTime: info.ModTime(), size: info.Size()}
+}
+
+// valid reports whether all the recorded inputs are unchanged in fsys.
+func (d *depRecorder) valid(fsys fs.FS) bool {
+	for name, old := range d.files {
+		if statDep(fs.Stat(fsys, name)) != old {
+			return false
+		}
+	}
+	for pattern, old := range d.globs {
+		matches, err := fs.Glob(fsys, pattern)
+		if err != nil || strings.Join(matches, "\n") != old.matches {
+			return false
+		}
+	}
+	return true
+}
+
+// modTime returns the latest modification time of the recorded files,
+// or the zero time if the rendering depended on anything else:
+// a glob, whose matches can change without any recorded file changing,
+// or an input that is not a file at all.
+// The modification time of a nil *depRecorder is the zero time.
+func (d *depRecorder) modTime() time.Time {
+	if d == nil || d.uncachable || len(d.globs) > 0 {
+		return time.Time{}
+	}
+	var max time.Time
+	for _, f := range d.files {
+		if f.modTime.After(max) {
+			max = f.modTime
+		}
+	}
+	return max
+}
+
+// An outputCache is a size-bounded LRU cache of rendered pages.
+type outputCache struct {
+	mu    sync.Mutex
+	max   int64                    // maximum total size of entries
+	size  int64                    // current total size of entries
+	lru   *list.List               // of *outputEntry, most recently used first
+	index map[string]*list.Element // key -> element in lru
+}
+
+// An outputEntry is a single rendered page in the output cache.
+type outputEntry struct {
+	key  string
+	html []byte
+	page Page // page that was rendered
+	deps *depRecorder
+}
+
+func (e *outputEntry) size() int64 {
+	return int64(len(e.key) + len(e.html) + 64*(len(e.deps.files)+len(e.deps.globs)))
+}
+
+// SetOutputCacheSize sets the maximum total size in bytes of the rendered
+// pages kept in the site's output cache. A size of 0 disables the cache.
+// SetOutputCacheSize must not be called concurrently with serving requests.
+func (s *Site) SetOutputCacheSize(size int64) {
+	c := &s.output
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.max = size
+	c.evict()
+}
+
+// get returns the cached entry for key, if it is present and
+// its inputs are unchanged in fsys.
+func (c *outputCache) get(key string, fsys fs.FS) *outputEntry {
+	c.mu.Lock()
+	elem := c.index[key]
+	if elem != nil {
+		c.lru.MoveToFront(elem)
+	}
+	c.mu.Unlock()
+	if elem == nil {
+		return nil
+	}
+	e := elem.Value.(*outputEntry)
+	if !e.deps.valid(fsys) {
+		c.mu.Lock()
+		if c.index[key] == elem {
+			c.remove(elem)
+		}
+		c.mu.Unlock()
+		return nil
+	}
+	return e
+}
+
+// put adds e to the cache, evicting old entries as needed.
+func (c *outputCache) put(e *outputEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e.size() > c.max {
+		return
+	}
+	if c.index == nil {
+		c.lru = list.New()
+		c.index = make(map[string]*list.Element)
+	}
+	if old := c.index[e.key]; old != nil {
+		c.remove(old)
+	}
+	c.index[e.key] = c.lru.PushFront(e)
+	c.size += e.size()
+	c.evict()
+}
+
+// remove removes elem from the cache. c.mu must be held.
+func (c *outputCache) remove(elem *list.Element) {
+	e := elem.Value.(*outputEntry)
+	c.lru.Remove(elem)
+	delete(c.index, e.key)
+	c.size -= e.size()
+}
+
+// evict removes least recently used entries until the cache fits in c.max.
+// c.mu must be held.
+func (c *outputCache) evict() {
+	for c.size > c.max && c.lru != nil && c.lru.Len() > 0 {
+		c.remove(c.lru.Back())
+	}
+}
+
+// serveCachedPage is like ServePage for the page loaded from pf,
+// but serves the page from the output cache when possible,
+// and otherwise renders it and adds the result to the cache.
+// The cache is keyed by the request URL path and query.
+func (s *Site) serveCachedPage(w http.ResponseWriter, r *http.Request, pf *pageFile) {
+	p := pf.page
+	key := r.URL.Path + "?" + r.URL.RawQuery
+	if e := s.output.get(key, s.fs); e != nil {
+		s.writePage(w, r, e.page, e.html, e.deps.modTime(), true)
+		return
+	}
+
+	deps := newDepRecorder()
+	deps.loaded(pf)
+	html, err := s.renderHTML(p, "site.tmpl", r, deps)
+	if err == errNoPageNumber {
+		s.ServeErrorStatus(w, r, err, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		s.serveErrorStatus(w, r, fmt.Errorf("template execution: %v", err), http.StatusInternalServerError, false)
+		return
+	}
+	if !deps.uncachable {
+		s.output.put(&outputEntry{key: key, html: html, page: p, deps: deps})
+	}
+	s.writePage(w, r, p, html, deps.modTime(), true)
+}
@@ -7,8 +7,10 @@ package web
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io/fs"
 	"path"
+	"sort"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -34,19 +36,7 @@ type Page map[string]interface{}
 
 func (site *Site) openPage(file string) (*pageFile, error) {
 	// Strip trailing .html or .md or /; it all names the same page.
-	if strings.HasSuffix(file, "/index.md") {
-		file = strings.TrimSuffix(file, "/index.md")
-	} else if strings.HasSuffix(file, "/index.html") {
-		file = strings.TrimSuffix(file, "/index.html")
-	} else if file == "index.md" || file == "index.html" {
-		file = "."
-	} else if strings.HasSuffix(file, "/") {
-		file = strings.TrimSuffix(file, "/")
-	} else if strings.HasSuffix(file, ".html") {
-		file = strings.TrimSuffix(file, ".html")
-	} else {
-		file = strings.TrimSuffix(file, ".md")
-	}
+	file = pageKey(file)
 
 	now := time.Now().UnixNano()
 	if cp, ok := site.cache.Load(file); ok {
@@ -65,7 +55,7 @@ func (site *Site) openPage(file string) (*pageFile, error) {
 
 	// Check md before html to work correctly when x/website is layered atop Go 1.15 goroot during Go 1.15 tests.
 	// Want to find x/website's debugging_with_gdb.md not Go 1.15's debuging_with_gdb.html.
-	files := []string{file + ".md", file + ".html", path.Join(file, "index.md"), path.Join(file, "index.html")}
+	files := site.pageFiles(file)
 	var filePath string
 	var b []byte
 	var err error
@@ -84,15 +74,30 @@ func (site *Site) openPage(file string) (*pageFile, error) {
 	}
 
 	// If we read an index.md or index.html, the canonical relpath is without the index.md/index.html suffix.
-	url := path.Join("/", file)
-	if name := path.Base(filePath); name == "index.html" || name == "index.md" {
-		url, _ = path.Split(path.Join("/", filePath))
+	// A translation like x.zh.md has the URL its default-language version would have, prefixed by /zh.
+	lang, plain := site.fileLang(filePath)
+	url := path.Join("/", pageKey(plain))
+	if name := path.Base(plain); name == "index.html" || name == "index.md" {
+		url, _ = path.Split(path.Join("/", plain))
+	}
+	if plain != filePath {
+		url = "/" + lang + url
 	}
 
+	schema, _ := site.metaSchema()
+	var lines map[string]int
+	if schema != nil {
+		lines = metaLines(b)
+	}
 	params, body, err := parseMeta(b)
 	if err != nil {
 		return nil, err
 	}
+	if schema != nil {
+		if errs := schema.check(filePath, params, lines); len(errs) > 0 {
+			site.logMetaErrors(filePath, stat, errs)
+		}
+	}
 
 	p := &pageFile{
 		file:    filePath,
@@ -107,6 +112,9 @@ func (site *Site) openPage(file string) (*pageFile, error) {
 	p.page["File"] = filePath
 	p.page["FileData"] = string(body)
 	p.page["URL"] = p.url
+	if lang != "" {
+		p.page["Lang"] = lang
+	}
 
 	// User-specified redirect: overrides url but not URL.
 	if redir, _ := p.page["redirect"].(string); redir != "" {
@@ -177,3 +185,31 @@ func parseMeta(b []byte) (meta Page, tail []byte, err error) {
 	tail = tail[end:]
 	return
 }
+
+// PageURLs returns the canonical URL paths of all the pages
+// loaded from files in the site's file system, in sorted order.
+// Pages that redirect elsewhere are omitted.
+func (site *Site) PageURLs() ([]string, error) {
+	var urls []string
+	err := fs.WalkDir(site.fs, ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(file, ".md") && !strings.HasSuffix(file, ".html") {
+			return nil
+		}
+		p, err := site.openPage(file)
+		if err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+		if _, ok := p.page["redirect"]; ok || p.file != file {
+			// Redirect, or file shadowed by another file for the same page
+			// (x.html by x.md, for example).
+			return nil
+		}
+		urls = append(urls, p.url)
+		return nil
+	})
+	sort.Strings(urls)
+	return urls, err
+}
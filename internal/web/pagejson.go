@@ -0,0 +1,168 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/goplus/website/internal/backport/html/template"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// wantsJSON reports whether the request asks for a page as JSON,
+// with the URL query parameter m=json or by listing application/json
+// first in its Accept header.
+func wantsJSON(r *http.Request) bool {
+	if r.FormValue("m") == "json" {
+		return true
+	}
+	accept := r.Header.Get("Accept")
+	if i := strings.IndexAny(accept, ",;"); i >= 0 {
+		accept = accept[:i]
+	}
+	return strings.TrimSpace(accept) == "application/json"
+}
+
+// A pageJSON is the JSON form of a page.
+// See the “JSON Pages” section of the package doc comment.
+type pageJSON struct {
+	URL          string
+	CanonicalURL string
+	Lang         string `json:",omitempty"`
+	Meta         map[string]interface{}
+	Content      string
+	TOC          []tocEntry
+	Paginator    *Paginator `json:",omitempty"`
+}
+
+// A tocEntry is a heading in a page's table of contents.
+type tocEntry struct {
+	Level int    // 2 for <h2>, and so on
+	ID    string `json:",omitempty"`
+	Title string // text of the heading
+}
+
+// pageJSONSkip lists the page keys that are not metadata:
+// they are either reported separately or internal details.
+var pageJSONSkip = map[string]bool{
+	"Content":   true,
+	"File":      true,
+	"FileData":  true,
+	"Lang":      true,
+	"Paginator": true,
+	"URL":       true,
+}
+
+// servePageJSON serves the page p, loaded from a file, as JSON.
+func (s *Site) servePageJSON(w http.ResponseWriter, r *http.Request, p Page) {
+	deps := newDepRecorder()
+	if file, _ := p["File"].(string); file != "" {
+		deps.stat(s.fs, file)
+	}
+	p, _, err := s.renderContent(p, "site.tmpl", r, deps, s.newRenderLimit(r))
+	if err != nil {
+		s.ServeError(w, r, fmt.Errorf("template execution: %v", err))
+		return
+	}
+	pager := p["Paginator"].(*Paginator)
+	if err := pager.check(); err != nil {
+		s.ServeErrorStatus(w, r, err, http.StatusNotFound)
+		return
+	}
+
+	url, _ := p["URL"].(string)
+	lang, _ := p["Lang"].(string)
+	content, _ := p["Content"].(template.HTML)
+	js := pageJSON{
+		URL:          url,
+		CanonicalURL: canonicalURL(r, url),
+		Lang:         lang,
+		Meta:         make(map[string]interface{}),
+		Content:      string(content),
+		TOC:          pageTOC(string(content)),
+	}
+	if pager.NumPages > 0 {
+		js.Paginator = pager
+	}
+	for k, v := range p {
+		if !pageJSONSkip[k] {
+			js.Meta[k] = v
+		}
+	}
+	data, err := json.MarshalIndent(js, "", "\t")
+	if err != nil {
+		s.ServeError(w, r, err)
+		return
+	}
+	data = append(data, '\n')
+
+	traceOf(r).setClass(ClassPage)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if s.checkNotModified(w, r, p, data, deps.modTime()) {
+		return
+	}
+	w.Write(data)
+}
+
+// canonicalURL returns the absolute URL for the page with the URL path url,
+// on the host named in the request r.
+func canonicalURL(r *http.Request, url string) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + url
+}
+
+// pageTOC returns the table of contents for the rendered page content:
+// the <h2>, <h3>, and <h4> headings, in order.
+func pageTOC(content string) []tocEntry {
+	toc := []tocEntry{}
+	nodes, err := html.ParseFragment(strings.NewReader(content), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		return toc
+	}
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && len(n.Data) == 2 && n.Data[0] == 'h' && '2' <= n.Data[1] && n.Data[1] <= '4' {
+			e := tocEntry{Level: int(n.Data[1] - '0'), Title: nodeText(n)}
+			for _, a := range n.Attr {
+				if a.Key == "id" {
+					e.ID = a.Val
+				}
+			}
+			toc = append(toc, e)
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+	return toc
+}
+
+// nodeText returns the text content of n,
+// with runs of white space collapsed to single spaces.
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
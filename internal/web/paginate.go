@@ -0,0 +1,176 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// A Paginator splits a listing of pages into numbered pages,
+// as returned by the {{paginate}} template function.
+// See the “Pagination” section of the package doc comment.
+type Paginator struct {
+	Number   int        // number of the page being rendered, starting at 1
+	NumPages int        // number of pages in the listing
+	Total    int        // number of items in the listing, across all pages
+	Pages    []Page     // items on the page being rendered
+	URL      string     // URL of the page being rendered
+	Prev     string     // URL of the previous page, or "" on the first page
+	Next     string     // URL of the next page, or "" on the last page
+	Links    []PageLink // every page in the listing, in order
+
+	base string // URL of the first page
+}
+
+// A PageLink is a link to one page of a paginated listing.
+type PageLink struct {
+	Number  int    // page number, starting at 1
+	URL     string // URL of the page
+	Current bool   // whether this is the page being rendered
+}
+
+// errNoPageNumber is the error for a request for a page number
+// beyond the end of a paginated listing, or of a page that is not paginated.
+var errNoPageNumber = errors.New("no such page number")
+
+type pageNumberKey struct{}
+
+// A pageNumberValue is the context value recorded by servePageNumber.
+type pageNumberValue struct {
+	url string // URL of the listing page
+	n   int    // requested page number
+}
+
+// pageNumber returns the page number of the listing page with the given URL
+// requested by r, as recorded by servePageNumber, or 1 if r is not for
+// a numbered page of that listing. Other pages rendered for r,
+// like an error page, are not numbered.
+func pageNumber(r *http.Request, url string) int {
+	if r != nil {
+		if v, ok := r.Context().Value(pageNumberKey{}).(pageNumberValue); ok && v.url == url {
+			return v.n
+		}
+	}
+	return 1
+}
+
+// servePageNumber serves a request for relpath,
+// which does not exist as a page, if relpath is a numbered page
+// of a paginated listing, like blog/page/2 for page 2 of blog.
+// It reports whether it served the request.
+func (s *Site) servePageNumber(w http.ResponseWriter, r *http.Request, relpath string) bool {
+	dir, num := path.Split(relpath)
+	n, err := strconv.Atoi(num)
+	if err != nil || n < 1 || strconv.Itoa(n) != num || path.Base(dir) != "page" {
+		return false
+	}
+	listing := path.Dir(strings.TrimSuffix(dir, "/"))
+	p, err := s.openPage(listing)
+	if err != nil {
+		return false
+	}
+
+	url := (&Paginator{base: p.url}).pageURL(n)
+	if r.URL.Path != url {
+		// Redirect to canonical path, which for page 1 is the listing itself.
+		u := *r.URL
+		u.Path = url
+		http.Redirect(w, r, u.String(), http.StatusMovedPermanently)
+		return true
+	}
+
+	traceOf(r).setClass(ClassPage)
+	r = r.WithContext(context.WithValue(r.Context(), pageNumberKey{}, pageNumberValue{p.url, n}))
+	s.serveHTML(w, r, p)
+	return true
+}
+
+// newPaginator returns the Paginator for the page with the given URL,
+// as rendered for the request r.
+func newPaginator(r *http.Request, url string) *Paginator {
+	pg := &Paginator{Number: pageNumber(r, url), base: url}
+	pg.URL = pg.pageURL(pg.Number)
+	return pg
+}
+
+// pageURL returns the URL of page n of the listing.
+func (pg *Paginator) pageURL(n int) string {
+	if n == 1 {
+		return pg.base
+	}
+	base := pg.base
+	if !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+	return base + "page/" + strconv.Itoa(n) + "/"
+}
+
+// paginate is the {{paginate key [order] size pages}} template function.
+// It fills in pg for the listing and returns pg.
+func (pg *Paginator) paginate(key string, args ...interface{}) (*Paginator, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, fmt.Errorf("paginate: want key, optional order, size, and pages")
+	}
+	size, ok := args[len(args)-2].(int)
+	if !ok || size < 1 {
+		return nil, fmt.Errorf("paginate: invalid page size %v", args[len(args)-2])
+	}
+	pages, ok := args[len(args)-1].([]Page)
+	if !ok {
+		return nil, fmt.Errorf("paginate: cannot paginate %T, only []Page", args[len(args)-1])
+	}
+	if key != "" {
+		var err error
+		sortArgs := append([]interface{}{}, args[:len(args)-2]...)
+		if pages, err = sortBy(key, append(sortArgs, pages)...); err != nil {
+			return nil, fmt.Errorf("paginate: %v", strings.TrimPrefix(err.Error(), "sortBy: "))
+		}
+	}
+
+	pg.Total = len(pages)
+	pg.NumPages = 1
+	if len(pages) > 0 {
+		pg.NumPages = (len(pages)-1)/size + 1
+	}
+	pg.Pages = nil
+	// Compare page numbers, not offsets, which could overflow
+	// for a huge page number taken from the URL.
+	if pg.Number <= pg.NumPages && len(pages) > 0 {
+		start := (pg.Number - 1) * size
+		end := len(pages)
+		if end-start > size {
+			end = start + size
+		}
+		pg.Pages = pages[start:end]
+	}
+	pg.Prev, pg.Next = "", ""
+	if pg.Number > 1 {
+		pg.Prev = pg.pageURL(pg.Number - 1)
+	}
+	if pg.Number < pg.NumPages {
+		pg.Next = pg.pageURL(pg.Number + 1)
+	}
+	pg.Links = nil
+	for n := 1; n <= pg.NumPages; n++ {
+		pg.Links = append(pg.Links, PageLink{Number: n, URL: pg.pageURL(n), Current: n == pg.Number})
+	}
+	return pg, nil
+}
+
+// check returns errNoPageNumber if the page being rendered
+// is beyond the end of the listing, including when the page
+// was rendered without calling {{paginate}} at all.
+func (pg *Paginator) check() error {
+	if pg.Number > pg.NumPages && pg.Number > 1 {
+		return errNoPageNumber
+	}
+	return nil
+}
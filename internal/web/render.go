@@ -7,12 +7,12 @@ package web
 import (
 	"bytes"
 	"fmt"
-	"io/fs"
 	"net/http"
 	"net/url"
 	"path"
 	"regexp"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/goplus/website/internal/backport/html/template"
@@ -29,7 +29,7 @@ import (
 // RenderContent returns the HTML rendering for the page using the named base template
 // (the standard base template is "site.tmpl").
 func (site *Site) RenderContent(p Page, tmpl string) (template.HTML, error) {
-	html, err := site.renderHTML(p, tmpl, &http.Request{URL: &url.URL{Path: "/missingurl"}})
+	html, err := site.renderHTML(p, tmpl, &http.Request{URL: &url.URL{Path: "/missingurl"}}, nil)
 	if err != nil {
 		return "", err
 	}
@@ -37,7 +37,31 @@ func (site *Site) RenderContent(p Page, tmpl string) (template.HTML, error) {
 }
 
 // renderHTML renders and returns the Content and framed HTML for the page.
-func (site *Site) renderHTML(p Page, tmpl string, r *http.Request) ([]byte, error) {
+// If deps is non-nil, renderHTML records in it the files the rendering reads.
+func (site *Site) renderHTML(p Page, tmpl string, r *http.Request, deps *depRecorder) ([]byte, error) {
+	limit := site.newRenderLimit(r)
+	p, t, err := site.renderContent(p, tmpl, r, deps, limit)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	html, err := limit.execute(t, p)
+	traceOf(r).since(phaseExecute, start)
+	if err != nil {
+		return nil, err
+	}
+	if err := p["Paginator"].(*Paginator).check(); err != nil {
+		return nil, err
+	}
+	return html, nil
+}
+
+// renderContent renders the Content for the page.
+// It returns a clone of p with the Content key-value pair set,
+// along with the parsed base template and layout, ready to frame it.
+// If deps is non-nil, renderContent records in it the files the rendering reads.
+// Executing the page's template is subject to limit.
+func (site *Site) renderContent(p Page, tmpl string, r *http.Request, deps *depRecorder, limit *renderLimit) (Page, *template.Template, error) {
 	// Clone p, because we are going to set its Content key-value pair.
 	p2 := make(Page)
 	for k, v := range p {
@@ -50,111 +74,146 @@ func (site *Site) renderHTML(p Page, tmpl string, r *http.Request) ([]byte, erro
 		// Set URL - caller did not.
 		p["URL"] = r.URL.Path
 	}
+	pager := newPaginator(r, p["URL"].(string))
+	p["Paginator"] = pager
 	file, _ := p["File"].(string)
 	data, _ := p["FileData"].(string)
 
+	dir := site.pageDir(url)
+	lang, _ := p["Lang"].(string)
+	sd := &siteDir{site, dir, lang, deps}
+
 	// Load base template.
-	base, err := site.readFile(".", tmpl)
+	base, err := sd.readFile(".", tmpl)
 	if err != nil {
-		return nil, err
-	}
-
-	dir := strings.Trim(path.Dir(url), "/")
-	if dir == "" {
-		dir = "."
+		return nil, nil, err
 	}
-	sd := &siteDir{site, dir}
 
-	t := template.New("site.tmpl").Funcs(template.FuncMap{
-		"add":      func(a, b int) int { return a + b },
-		"sub":      func(a, b int) int { return a - b },
-		"mul":      func(a, b int) int { return a * b },
-		"div":      func(a, b int) int { return a / b },
-		"code":     sd.code,
-		"data":     sd.data,
-		"page":     sd.page,
-		"pages":    sd.pages,
-		"play":     sd.play,
-		"request":  func() *http.Request { return r },
-		"path":     func() pkgPath { return pkgPath{} },
-		"strings":  func() pkgStrings { return pkgStrings{} },
-		"file":     sd.file,
-		"first":    first,
-		"markdown": markdown,
-		"raw":      raw,
-		"yaml":     yamlFn,
-	})
-	t.Funcs(site.funcs)
+	t := template.New("site.tmpl").Funcs(limit.funcs(template.FuncMap{
+		"add":          func(a, b int) int { return a + b },
+		"asset":        sd.asset,
+		"sub":          func(a, b int) int { return a - b },
+		"mul":          func(a, b int) int { return a * b },
+		"div":          func(a, b int) int { return a / b },
+		"code":         sd.code,
+		"collection":   sd.collection,
+		"data":         sd.data,
+		"date":         dateFn,
+		"default":      defaultFn,
+		"dict":         dict,
+		"groupBy":      groupBy,
+		"jsonify":      jsonify,
+		"list":         listFn,
+		"now":          func() time.Time { deps.noCache(); return time.Now() },
+		"page":         sd.page,
+		"paginate":     pager.paginate,
+		"pages":        sd.pages,
+		"play":         sd.play,
+		"request":      func() *http.Request { deps.noCache(); return r },
+		"sortBy":       sortBy,
+		"path":         func() pkgPath { return pkgPath{} },
+		"strings":      func() pkgStrings { return pkgStrings{} },
+		"translations": func() []Translation { return sd.translations(p) },
+		"where":        where,
+		"file":         sd.file,
+		"first":        first,
+		"markdown":     markdown,
+		"raw":          raw,
+		"yaml":         yamlFn,
+	}))
+	t.Funcs(limit.funcs(site.funcs))
 
-	if err := tmplfunc.Parse(t, string(base)); err != nil {
-		return nil, err
+	trace := traceOf(r)
+	start := time.Now()
+	err = tmplfunc.Parse(t, string(base))
+	trace.since(phaseParse, start)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Load page-specific layout template.
-	layout, _ := p["layout"].(string)
-	if layout == "" {
-		l, ok := site.findLayout(dir, "default")
-		if ok {
-			layout = l
-		} else {
-			layout = "none"
-		}
-	} else if path.IsAbs(layout) {
-		layout = strings.TrimLeft(path.Clean(layout+".tmpl"), "/")
-	} else if strings.Contains(layout, "/") {
-		layout = path.Join(dir, layout+".tmpl")
-	} else if layout != "none" {
-		l, ok := site.findLayout(dir, layout)
-		if !ok {
-			return nil, fmt.Errorf("cannot find layout %q", layout)
-		}
-		layout = l
+	layout, err := site.pageLayout(p, dir, deps)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	if layout != "none" {
-		ldata, err := site.readFile(".", layout)
+		ldata, err := sd.readFile(".", layout)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		if err := tmplfunc.Parse(t.New(layout), string(ldata)); err != nil {
-			return nil, err
+		start := time.Now()
+		err = tmplfunc.Parse(t.New(layout), string(ldata))
+		trace.since(phaseParse, start)
+		if err != nil {
+			return nil, nil, err
 		}
 	}
 
-	var buf bytes.Buffer
 	if _, ok := p["Content"]; !ok && data != "" {
 		// Load actual Markdown content (also a template).
 		tf := t.New(file)
-		if err := tmplfunc.Parse(tf, data); err != nil {
-			return nil, err
+		start := time.Now()
+		err := tmplfunc.Parse(tf, data)
+		trace.since(phaseParse, start)
+		if err != nil {
+			return nil, nil, err
 		}
-		if err := tf.Execute(&buf, p); err != nil {
-			return nil, err
+		start = time.Now()
+		out, err := limit.execute(tf, p)
+		trace.since(phaseExecute, start)
+		if err != nil {
+			return nil, nil, err
 		}
 		if strings.HasSuffix(file, ".md") {
-			html, err := markdownToHTML(buf.String())
+			start := time.Now()
+			html, err := markdownToHTML(string(out))
+			trace.since(phaseMarkdown, start)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			p["Content"] = html
 		} else {
-			p["Content"] = template.HTML(buf.String())
+			p["Content"] = template.HTML(out)
 		}
-		buf.Reset()
 	}
 
-	if err := t.Execute(&buf, p); err != nil {
-		return nil, err
+	return p, t, nil
+}
+
+// pageLayout returns the file name of the layout template for the page p,
+// whose URL is in the directory dir, or "none" if the page uses no layout.
+// If deps is non-nil, pageLayout records in it the files it looks for.
+func (site *Site) pageLayout(p Page, dir string, deps *depRecorder) (string, error) {
+	layout, _ := p["layout"].(string)
+	if layout == "" {
+		l, ok := site.findLayout(dir, "default", deps)
+		if ok {
+			layout = l
+		} else {
+			layout = "none"
+		}
+	} else if path.IsAbs(layout) {
+		layout = strings.TrimLeft(path.Clean(layout+".tmpl"), "/")
+	} else if strings.Contains(layout, "/") {
+		layout = path.Join(dir, layout+".tmpl")
+	} else if layout != "none" {
+		l, ok := site.findLayout(dir, layout, deps)
+		if !ok {
+			return "", fmt.Errorf("cannot find layout %q", layout)
+		}
+		layout = l
 	}
-	return buf.Bytes(), nil
+	return layout, nil
 }
 
 // findLayout searches the start directory and parent directories for a template with the given base name.
-func (site *Site) findLayout(dir, name string) (string, bool) {
+// If deps is non-nil, findLayout records in it each file it looks for.
+func (site *Site) findLayout(dir, name string, deps *depRecorder) (string, bool) {
 	name += ".tmpl"
 	for {
 		abs := path.Join(dir, name)
-		if _, err := fs.Stat(site.fs, abs); err == nil {
+		if _, err := deps.stat(site.fs, abs); err == nil {
 			return abs, true
 		}
 		if dir == "." {
@@ -184,6 +243,8 @@ func markdownToHTML(markdown string) (template.HTML, error) {
 				extension.WithLinkifyEmailRegexp(regexp.MustCompile(`[^\x00-\x{10FFFF}]`)), // impossible
 			),
 			extension.DefinitionList,
+			extension.Footnote,
+			mdExtensions{},
 		),
 	)
 	var buf bytes.Buffer
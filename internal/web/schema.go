@@ -0,0 +1,407 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package web
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// schemaFile is the name of the metadata schema file in the site's root.
+const schemaFile = "schema.yaml"
+
+// A metaSchema lists the metadata keys allowed in pages, by directory.
+type metaSchema struct {
+	dirs map[string]map[string]string // directory ("." for root) -> lower-case key -> type
+}
+
+// parseSchema parses the schema file data.
+func parseSchema(data []byte) (*metaSchema, error) {
+	var raw map[string]map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %v", schemaFile, err)
+	}
+	s := &metaSchema{dirs: make(map[string]map[string]string)}
+	var errs []string
+	for dir, keys := range raw {
+		if !strings.HasPrefix(dir, "/") {
+			errs = append(errs, fmt.Sprintf("%s: directory %q does not begin with /", schemaFile, dir))
+			continue
+		}
+		clean := strings.Trim(path.Clean(dir), "/")
+		if clean == "" {
+			clean = "."
+		}
+		m := make(map[string]string)
+		for key, t := range keys {
+			typ, ok := t.(string)
+			if list, isList := t.([]interface{}); isList && len(list) == 1 {
+				// [T], written without quotes, parses as a YAML list.
+				var elem string
+				elem, ok = list[0].(string)
+				typ = "[" + elem + "]"
+			}
+			if !ok || !validType(typ) {
+				errs = append(errs, fmt.Sprintf("%s: %s: key %q has unknown type %v", schemaFile, dir, key, t))
+			}
+			m[strings.ToLower(key)] = typ
+		}
+		s.dirs[clean] = m
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return nil, fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return s, nil
+}
+
+// validType reports whether typ is a type name understood by checkType.
+func validType(typ string) bool {
+	if strings.HasPrefix(typ, "[") && strings.HasSuffix(typ, "]") {
+		return validType(typ[1 : len(typ)-1])
+	}
+	switch typ {
+	case "string", "int", "number", "bool", "date", "list", "map", "any":
+		return true
+	}
+	return false
+}
+
+// checkType reports whether the metadata value v has the schema type typ.
+func checkType(v interface{}, typ string) bool {
+	if strings.HasPrefix(typ, "[") && strings.HasSuffix(typ, "]") {
+		list, ok := v.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, x := range list {
+			if !checkType(x, typ[1:len(typ)-1]) {
+				return false
+			}
+		}
+		return true
+	}
+	switch typ {
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "int":
+		switch v := v.(type) {
+		case int:
+			return true
+		case float64: // JSON
+			return v == float64(int(v))
+		}
+		return false
+	case "number":
+		switch v.(type) {
+		case int, float64:
+			return true
+		}
+		return false
+	case "bool":
+		_, ok := v.(bool)
+		return ok
+	case "date":
+		switch v := v.(type) {
+		case time.Time:
+			return true
+		case string:
+			_, err := time.Parse("2006-01-02", v)
+			if err != nil {
+				_, err = time.Parse(time.RFC3339, v)
+			}
+			return err == nil
+		}
+		return false
+	case "list":
+		_, ok := v.([]interface{})
+		return ok
+	case "map":
+		_, ok := v.(map[string]interface{})
+		return ok
+	case "any":
+		return true
+	}
+	return false
+}
+
+// typeName returns the schema type name describing the metadata value v.
+func typeName(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return "string"
+	case int:
+		return "int"
+	case float64:
+		if v == float64(int(v)) {
+			return "int"
+		}
+		return "number"
+	case bool:
+		return "bool"
+	case time.Time:
+		return "date"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "map"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// keys returns the keys allowed in pages in the file's directory:
+// those listed for that directory and all its parents.
+func (s *metaSchema) keys(file string) map[string]string {
+	keys := make(map[string]string)
+	for dir := path.Dir(file); ; dir = path.Dir(dir) {
+		for k, t := range s.dirs[dir] {
+			if _, ok := keys[k]; !ok {
+				keys[k] = t
+			}
+		}
+		if dir == "." {
+			break
+		}
+	}
+	return keys
+}
+
+// check checks the metadata meta of the page in file against the schema,
+// returning one error for each unknown key or value of the wrong type.
+// The lines map gives the line number of each key in the file.
+func (s *metaSchema) check(file string, meta Page, lines map[string]int) []error {
+	keys := s.keys(file)
+	var errs []error
+	for k, v := range meta {
+		pos := file
+		if line := lines[strings.ToLower(k)]; line > 0 {
+			pos = fmt.Sprintf("%s:%d", file, line)
+		}
+		typ, ok := keys[strings.ToLower(k)]
+		if !ok {
+			msg := fmt.Sprintf("%s: unknown metadata key %q", pos, k)
+			if near := nearest(strings.ToLower(k), keys); near != "" {
+				msg += fmt.Sprintf(" (did you mean %q?)", near)
+			}
+			errs = append(errs, fmt.Errorf("%s", msg))
+			continue
+		}
+		if !checkType(v, typ) {
+			errs = append(errs, fmt.Errorf("%s: metadata key %q has type %s, want %s", pos, k, typeName(v), typ))
+		}
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errs
+}
+
+// nearest returns the key in keys closest to k in edit distance,
+// or "" if none is close enough to be a likely typo.
+func nearest(k string, keys map[string]string) string {
+	best, bestDist := "", 3
+	for key := range keys {
+		if d := editDistance(k, key); d < bestDist || d == bestDist && key < best {
+			best, bestDist = key, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Damerau-Levenshtein distance between a and b,
+// counting a transposition of adjacent characters as a single edit.
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, minInt(d[i][j-1]+1, d[i-1][j-1]+cost))
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func minInt(x, y int) int {
+	if x < y {
+		return x
+	}
+	return y
+}
+
+var jsonKey = regexp.MustCompile(`"([^"\\]+)"\s*:`)
+
+// metaLines returns the line numbers of the top-level keys in the
+// metadata block at the top of the file contents b, keyed by lower-case key.
+// It must be called before parseMeta, which overwrites part of the block.
+func metaLines(b []byte) map[string]int {
+	lines := make(map[string]int)
+	if bytes.HasPrefix(b, jsonStart) {
+		end := bytes.Index(b, jsonEnd)
+		if end < 0 {
+			return lines
+		}
+		for i, line := range bytes.Split(b[:end], []byte("\n")) {
+			for _, m := range jsonKey.FindAllSubmatch(line, -1) {
+				k := strings.ToLower(string(m[1]))
+				if _, ok := lines[k]; !ok {
+					lines[k] = i + 1
+				}
+			}
+		}
+	} else if bytes.HasPrefix(b, yamlStart) {
+		end := bytes.Index(b, yamlEnd)
+		if end < 0 {
+			return lines
+		}
+		var doc yaml.Node
+		if yaml.Unmarshal(b[len(yamlStart):end+1], &doc) != nil || len(doc.Content) == 0 {
+			return lines
+		}
+		m := doc.Content[0]
+		for i := 0; i+1 < len(m.Content); i += 2 {
+			// +1 for the opening --- line.
+			lines[strings.ToLower(m.Content[i].Value)] = m.Content[i].Line + 1
+		}
+	}
+	return lines
+}
+
+// A schemaCache holds a site's metadata schema, reloaded when the schema file changes.
+type schemaCache struct {
+	mu      sync.Mutex
+	checked int64 // unix nano, atomically updated
+	stat    dep   // state of the schema file when loaded
+	schema  *metaSchema
+	err     error
+	logged  map[string]dep // page file -> state when its errors were last logged
+}
+
+// metaSchema returns the site's metadata schema,
+// or nil if the site has no schema file.
+func (s *Site) metaSchema() (*metaSchema, error) {
+	c := &s.schema
+	// To avoid continuous stats, only check it has been 3s since the last one.
+	now := time.Now().UnixNano()
+	if last := atomic.LoadInt64(&c.checked); now-last >= 3e9 && atomic.CompareAndSwapInt64(&c.checked, last, now) {
+		info, err := fs.Stat(s.fs, schemaFile)
+		st := statDep(info, err)
+		c.mu.Lock()
+		if last == 0 || st != c.stat {
+			c.stat = st
+			c.schema, c.err = s.loadSchema()
+			if c.err != nil {
+				log.Print(c.err)
+			}
+		}
+		c.mu.Unlock()
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.schema, c.err
+}
+
+// loadSchema loads the site's metadata schema,
+// returning nil, nil if the site has no schema file.
+func (s *Site) loadSchema() (*metaSchema, error) {
+	data, err := fs.ReadFile(s.fs, schemaFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseSchema(data)
+}
+
+// logMetaErrors logs errs, the schema errors in the page file loaded
+// with the given stat, unless they have already been logged for
+// this version of the file.
+func (s *Site) logMetaErrors(file string, stat fs.FileInfo, errs []error) {
+	c := &s.schema
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	st := statDep(stat, nil)
+	if c.logged[file] == st {
+		return
+	}
+	if c.logged == nil {
+		c.logged = make(map[string]dep)
+	}
+	c.logged[file] = st
+	for _, err := range errs {
+		log.Print(err)
+	}
+}
+
+// CheckPages checks the metadata of every page in the site
+// against the schema in the site's schema.yaml file, if any,
+// and returns the errors found, each prefixed by the page's file name
+// and line number.
+// It also reports pages whose metadata cannot be parsed at all,
+// and Markdown pages with invalid code block attributes.
+func (s *Site) CheckPages() []error {
+	schema, err := s.loadSchema()
+	if err != nil {
+		return []error{err}
+	}
+	var errs []error
+	fs.WalkDir(s.fs, ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		if d.IsDir() || !strings.HasSuffix(file, ".md") && !strings.HasSuffix(file, ".html") {
+			return nil
+		}
+		b, err := fs.ReadFile(s.fs, file)
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		var codeErrs []error
+		if strings.HasSuffix(file, ".md") {
+			// Before parseMeta, which may overwrite b.
+			codeErrs = checkCodeBlocks(file, b)
+		}
+		lines := metaLines(b)
+		meta, _, err := parseMeta(b)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: parsing metadata: %v", file, err))
+			return nil
+		}
+		if schema != nil {
+			errs = append(errs, schema.check(file, meta, lines)...)
+		}
+		errs = append(errs, codeErrs...)
+		return nil
+	})
+	return errs
+}
@@ -48,6 +48,14 @@
 // The key-value pair “redirect: url” causes requests for this page redirect to the given
 // relative or absolute URL.
 //
+// The key-value pair “aliases: [url, ...]” lists old URLs for the page,
+// typically the URLs it was served at before being moved or renamed.
+// Requests for those URLs redirect (with status 301) to the page's canonical URL.
+// As with other file paths, an alias not beginning with a slash is
+// interpreted relative to the directory containing the page's file.
+// An alias naming a page or file that exists, or listed by more than one page,
+// is a conflict: it is ignored and reported by Site.CheckAliases.
+//
 // The key-value pair “layout: name” selects the page layout template with the given name.
 // See the next section, “Page Rendering”, for details about layout and rendering.
 //
@@ -57,9 +65,37 @@
 //	- File: the path in fsys to the file containing the page
 //	- FileData: the file body, with the key-value metadata stripped
 //	- URL: this page's URL path (/x/y/z for x/y/z.md, /x/y/ for x/y/index.md)
-//
-// The key “Content” is added during during the rendering process.
-// See “Page Rendering” for details.
+//	- Lang: the page's language, if the site has languages (see “Languages” below)
+//
+// The keys “Content” and “Paginator” are added during the rendering process.
+// See “Page Rendering” and “Pagination” for details.
+//
+// Metadata Schema
+//
+// An optional file “schema.yaml” in the root of fsys lists the metadata keys
+// pages may use, and the type of each key's value, by directory:
+//
+//	# schema.yaml
+//	/:
+//	  title: string
+//	  layout: string
+//	  status: int
+//	  aliases: [string]
+//	/blog/:
+//	  date: date
+//	  authors: [string]
+//
+// The keys listed for a directory are allowed in pages in that directory and below.
+// Keys are matched without regard to case, since keys in JSON metadata are
+// converted to lower case.
+// The types are string, int, number, bool, date (a YAML date or a string
+// like 2021-01-02), list, map, and any, along with [T] for a list of values of type T.
+//
+// When a site has a schema, each page is checked against it as it is loaded.
+// Unknown keys, often typos like “layuot”, and values of the wrong type, like
+// a quoted “status”, are logged as errors of the form file:line: message.
+// The page is still served.
+// Site.CheckPages checks every page in the site at once.
 //
 // Page Rendering
 //
@@ -106,6 +142,74 @@
 // if there is no layout-specific template,
 // the content will still be rendered.
 //
+// Markdown Extensions
+//
+// Besides standard Markdown, pages can use tables, footnotes, and definition lists,
+// written as in GitHub and PHP Markdown Extra:
+//
+//	Go+ has rational numbers[^1].
+//
+//	[^1]: Written with an r suffix, as in 1/3r.
+//
+//	Term
+//	: Definition of the term.
+//
+// An admonition is a callout box, introduced by a line “!!! kind "Title"”
+// and containing the Markdown indented by four spaces on the following lines:
+//
+//	!!! warning "Breaking change"
+//	    The old syntax is no longer accepted.
+//
+// It renders as <div class="Admonition Admonition--warning">,
+// beginning with <p class="Admonition-title">Breaking change</p>.
+// The title may be omitted, in which case it is the kind with an initial capital
+// (here, “Warning”), or it may be "", in which case there is no title paragraph.
+// The site's style sheet styles the kinds note, tip, warning, caution, and danger.
+//
+// A tab is introduced by a line “=== "Title"” and likewise contains
+// the Markdown indented on the following lines.
+// Consecutive tabs form a tab set, of which only one tab's content is shown at a time:
+//
+//	=== "Go"
+//	    ```
+//	    fmt.Println("Hello")
+//	    ```
+//
+//	=== "Go+"
+//	    ```
+//	    println "Hello"
+//	    ```
+//
+// A tab set renders as a <div class="TabSection js-tabs"> holding a
+// TabSection-tabList of buttons followed by one TabSection-tabPanel per tab,
+// all but the first hidden. The site's JavaScript switches panels
+// when a tab is clicked.
+//
+// Fenced code blocks in Go and Go+, those with info strings beginning “go” or “gop”,
+// are formatted like the output of {{code}}, with comments highlighted,
+// in a <div class="code language-go"> (or language-gop).
+// Attributes following the language in the info string adjust the formatting:
+// “linenos” numbers the lines, starting at 1 (or at N, for “linenos=N”),
+// and “hl_lines=3-5” highlights lines 3 through 5 of the block;
+// the value can list several lines and ranges, as in “hl_lines=1,3-5”.
+// The attribute “play” instead renders the block as a runnable example,
+// like the ones in package documentation, ignoring any other attributes.
+// Go blocks run in the site's own playground backend (its /compile handler),
+// and Go+ blocks run in the Go+ playground at https://play.goplus.org.
+// For example:
+//
+//	```go linenos hl_lines=5
+//	package main
+//
+//	import "fmt"
+//
+//	func main() { fmt.Println("Hello") }
+//	```
+//
+// Fenced code blocks in other languages are rendered as plain <pre><code> blocks,
+// as are Go and Go+ blocks with unknown or invalid attributes.
+// Site.CheckPages reports those mistakes, and rendering logs them.
+//
 // Page Template Functions
 //
 // In this web server, templates can themselves be invoked as functions.
@@ -122,6 +226,13 @@
 // The “{{add x y}}”, “{{sub x y}}”, “{{mul x y}}”, and “{{div x y}}” functions
 // provide basic math on arguments of type int.
 //
+// The “{{asset f}}” function returns a fingerprinted URL path for the file f,
+// with a hash of the file's content inserted before its extension,
+// as in “/lib/godoc/style.0123456789ab.css” for “/lib/godoc/style.css”.
+// The Site serves that URL from f, with a Cache-Control header allowing
+// clients to cache it forever, since a change to f's content changes the URL.
+// A URL with an out-of-date hash is served from f too, but without that header.
+//
 // The “{{code f [start [end]]}}” function returns a template.HTML of a formatted display
 // of code lines from the file f.
 // If both start and end are omitted, then the display shows the entire file.
@@ -137,25 +248,67 @@
 //
 //	{{code "hello.go" `^func main` `^}`}}
 //
+// The “{{collection d}}” function returns the records (a []Page) in the data
+// collection stored in the directory d. See the “Data Collections” section below.
+//
 // The “{{data f}}” function reads the file f,
 // decodes it as YAML, and then returns the resulting data,
 // typically a map[string]interface{}.
 // It is effectively shorthand for “{{yaml (file f)}}”.
 //
+// The “{{date layout t}}” function formats the time t using the layout
+// of the time package, as in “{{date "Jan 2, 2006" .date}}”.
+// The time t can be a time.Time (such as a date in YAML metadata)
+// or a string holding a date like “2021-05-01” or “2021-05-01T10:00:00Z”.
+// If t is nil, as for missing metadata, the result is an empty string.
+//
+// The “{{default def x}}” function returns x if x is true in the sense of {{if}}
+// (not the zero value or empty), and def otherwise.
+// It reads best in a pipeline, as in “{{.title | default "Untitled"}}”.
+//
+// The “{{dict key value ...}}” function returns a map[string]interface{}
+// holding the given key-value pairs, and
+// the “{{list x ...}}” function returns a []interface{} holding its arguments.
+// They are useful for passing several arguments to a template invoked as a function.
+//
 // The “{{file f}}” function reads the file f and returns its content as a string.
 //
 // The “{{first n slice}}” function returns a slice of the first n elements of slice,
 // or else slice itself when slice has fewer than n elements.
 //
+// The “{{groupBy key pages}}” function splits pages (a []Page) into groups
+// of pages sharing the same value for the metadata key.
+// It returns a []PageGroup, in order of each group's first page.
+// Each PageGroup has fields Key (the shared value) and Pages.
+// For example, to list articles by year:
+//
+//	{{range (pages "/articles/*" | sortBy "year" "desc" | groupBy "year")}}
+//	## {{.Key}}
+//	{{range .Pages}}- [{{.title}}]({{.URL}})
+//	{{end}}
+//	{{end}}
+//
+// The “{{jsonify x}}” function returns x encoded as JSON,
+// for use in a <script> element or a data attribute.
+//
 // The “{{markdown text}}” function interprets text (a string) as Markdown
 // and returns the equivalent HTML as a template.HTML.
 //
+// The “{{now}}” function returns the current time.
+// Like {{request}}, it makes the page depend on more than its files,
+// so a page that calls {{now}} is never kept in the output cache.
+//
 // The “{{page f}}” function returns the page data (a Page)
 // for the static page contained in the file f.
 // The lookup ignores trailing slashes in f as well as the presence or absence
 // of extensions like .md, .html, /index.md, and /index.html,
 // making it possible for f to be a relative or absolute URL path instead of a file path.
 //
+// The “{{paginate key [order] size pages}}” function splits pages (a []Page)
+// into numbered pages of size items each, after sorting them like {{sortBy}}
+// (an empty key keeps their order), and returns the page's Paginator.
+// See the “Pagination” section below.
+//
 // The “{{pages glob}}” function returns a slice of page data (a []Page)
 // for all pages loaded from files or directories
 // in fsys matching the given glob (a string),
@@ -172,9 +325,39 @@
 //	- [{{.title}}]({{.URL}})
 //	{{end}}
 //
+// When the page being rendered has a language other than the default,
+// the pages listed by {{pages}} are the translations into that language
+// where they exist, and the default-language pages otherwise.
+//
 // The “{{raw s}}” function converts s (a string) to type template.HTML without any escaping,
 // to allow using s as raw Markdown or HTML in the final output.
 //
+// The “{{sortBy key [order] pages}}” function returns a copy of pages (a []Page)
+// sorted by the value of the metadata key, in "asc" (the default) or "desc" order.
+// Times and numbers sort by value and other values by their string form.
+// Pages without the key sort last, and pages with equal values keep their order.
+// For example, “{{pages "/blog/*" | sortBy "date" "desc"}}” lists the newest posts first.
+//
+// The “{{translations}}” function returns the available language versions
+// of the page being rendered (a []Translation), in the order given to Site.SetLanguages.
+// Each Translation has fields Lang, URL, Link (the URL with a ?lang= parameter
+// recording the reader's choice), and Current (whether it is the page being rendered).
+// For example:
+//
+//	{{range translations}}
+//	<link rel="alternate" hreflang="{{.Lang}}" href="{{.URL}}">
+//	{{end}}
+//
+// The “{{where key [op] value pages}}” function returns the pages (a []Page)
+// whose value for the metadata key satisfies the comparison op with value.
+// The op is one of ==, !=, <, <=, >, and >=, defaulting to ==,
+// comparing times and numbers by value and other values by their string form,
+// or else “contains”, which matches a string value containing value
+// as a substring or a list value with an element equal to value.
+// When the key's value is a time, a string value is parsed as a date.
+// Pages without the key match only !=.
+// For example, “{{pages "/talks/*" | where "date" ">=" "2021-01-01"}}”.
+//
 // The “{{yaml s}}” function decodes s (a string) as YAML and returns the resulting data.
 // It is most useful for defining templates that accept YAML-structured data as a literal argument.
 // For example:
@@ -194,6 +377,72 @@
 // function in these packages (except path.Split, which has more than one non-error result
 // and would not be invokable). For example, “{{strings.ToUpper "abc"}}”.
 //
+// The functions predefined by the template package, such as “{{urlquery s}}”,
+// “{{printf format x ...}}”, “{{slice x i j}}”, and “{{len x}}”, are available as usual.
+//
+// So that a buggy template does not hold up its request indefinitely, rendering a page
+// is limited to 10 seconds of template execution and 16 MB of rendered content and of framed HTML.
+// A page exceeding either limit is served as an error instead.
+// Use Site.SetRenderLimits to change the limits.
+// The time limit does not protect the server itself: the template package cannot
+// interrupt an execution, so one that runs out of time is abandoned, not stopped.
+// It stops at its next output or template function call, but a loop doing neither
+// keeps running in the background. Trace.Abandoned counts these executions.
+//
+// Data Collections
+//
+// A data collection is a directory of YAML, JSON, or CSV files holding records
+// that pages can list, such as /data/users or /data/events.
+// A YAML or JSON file (named *.yaml, *.yml, or *.json) holds a single record,
+// which is a map from field names to values, or a list of records.
+// A CSV file (*.csv) holds a header line naming the fields
+// and then one line per record; fields that look like numbers
+// are decoded as numbers. Other files and subdirectories are ignored.
+//
+// The “{{collection d}}” template function returns the collection's records
+// as a []Page, ordered by file name and then by their order in each file,
+// so that the page functions {{sortBy}}, {{groupBy}}, {{where}}, and {{first}}
+// can filter, sort, and limit them. For example:
+//
+//	{{range (collection "/data/users" | where "tags" "contains" "education" | sortBy "name" | first 10)}}
+//	- [{{.name}}]({{.url}})
+//	{{end}}
+//
+// The Site loads each collection once and keeps the records in memory,
+// reloading them when a file in the directory is created, modified, or removed.
+// A page listing a collection is likewise re-rendered when the collection changes.
+// Site.Collection returns a collection's records for use outside templates.
+//
+// Pagination
+//
+// A page listing many other pages, such as a blog index, can spread the listing
+// over numbered pages. The first page is served at the page's own URL,
+// such as /blog/, and page N at the URL with “page/N/” appended, such as /blog/page/2/.
+// The Site serves those URLs by rendering the listing page again.
+//
+// During rendering, the Page key “Paginator” holds a *Paginator for the page,
+// with the requested page number in its Number field.
+// The {{paginate}} template function fills in the rest:
+// the items on the current page (Pages), the number of pages (NumPages)
+// and items (Total), the URLs of the current, previous, and next pages
+// (URL, Prev, Next), and a PageLink for every page (Links).
+// For example, a blog index might list ten posts per page, newest first:
+//
+//	{{with paginate "date" "desc" 10 (pages "/blog/*")}}
+//	{{range .Pages}}- [{{.title}}]({{.URL}})
+//	{{end}}
+//	{{with .Prev}}[Newer posts]({{.}}){{end}}
+//	{{with .Next}}[Older posts]({{.}}){{end}}
+//	{{end}}
+//
+// Since the Paginator is part of the page, the site template can also use it,
+// as in “{{with .Paginator.Next}}<link rel="next" href="{{.}}">{{end}}”.
+//
+// A request for a page number beyond the end of the listing,
+// or for a numbered page of a page that does not call {{paginate}},
+// receives a 404 (not found) response. A request for /blog/page/1/
+// redirects to /blog/.
+//
 // Serving Requests
 //
 // A Site is an http.Handler that serves requests by consulting the underlying
@@ -266,6 +515,95 @@
 // where err is the “not exist” error returned by fs.Stat(fsys, p).
 // (See also the “Serving Errors” section below.)
 //
+// JSON Pages
+//
+// A page rendered from a file can also be served as JSON, for use by
+// scripts and other clients, when the request has the URL query parameter
+// m=json or lists application/json first in its Accept header.
+// The response is a JSON object with these fields:
+//
+//	URL           the page's URL path
+//	CanonicalURL  the page's absolute URL, using the request's host
+//	Lang          the page's language, if the site has languages
+//	Meta          the page's metadata, excluding the keys listed here and File
+//	Content       the page's rendered HTML, without the site template framing
+//	TOC           the page's <h2>, <h3>, and <h4> headings, in order,
+//	              each an object with Level (2, 3, or 4), ID, and Title fields
+//	Paginator     the page's Paginator, if the page's content calls {{paginate}}
+//
+// JSON responses carry ETag and Last-Modified headers like HTML pages
+// but are not kept in the output cache. Since the same URL can serve HTML or JSON,
+// every page response includes “Vary: Accept”.
+//
+// Caching
+//
+// Every successfully rendered page, directory listing, and text file page
+// is served with an ETag header holding a hash of the response body.
+// Pages and text files rendered from files also get a Last-Modified header
+// holding the latest modification time of the files read to render them
+// (the page's file, the templates, and files read by template functions),
+// unless the rendering depends on more than those files, as when it lists
+// pages matching a {{pages}} pattern or calls {{now}} or {{request}}.
+// Those responses rely on the ETag alone.
+// Requests with If-None-Match or If-Modified-Since headers matching the
+// current response receive a 304 (not modified) response instead.
+//
+// The key-value pair “cache-control: policy” in a page's metadata sets the
+// Cache-Control header for that page, as in “cache-control: public, max-age=3600”.
+// A policy set in a directory's index page also applies to everything below
+// that directory (pages, directory listings, and text files) that does not
+// set a policy of its own.
+//
+// The Site also keeps the HTML of pages rendered from files in an output cache,
+// keyed by request URL path and query. While rendering a page, the Site records
+// every file the rendering reads: the page itself, the site and layout templates
+// (including the layout files looked for but not found), and files read by the
+// {{code}}, {{collection}}, {{data}}, {{file}}, {{page}}, {{pages}}, and {{play}} template functions.
+// A cached page is served until one of those files is created, modified, or removed,
+// or until the set of files matched by a {{pages}} pattern changes.
+// Pages that call {{request}} depend on more than their URL and are never cached.
+// Functions added with Site.Funcs are assumed to depend only on their arguments.
+// The cache holds at most 32 MB of pages by default; use Site.SetOutputCacheSize
+// to change the limit.
+//
+// Languages
+//
+// A Site configured with Site.SetLanguages (for example, SetLanguages("en", "zh"))
+// serves content written in several languages.
+// The first language is the default, used for pages like doc/x.md.
+// The translation of doc/x.md into another language, say zh, is found in
+// zh/doc/x.md or in doc/x.zh.md (and similarly for .html and index files),
+// and served at /zh/doc/x.
+// Every page loaded from a file has a “Lang” key giving its language.
+// Relative file paths in a translation, including those used to find its
+// layout template, are interpreted as in its default-language version:
+// a glob "*" in zh/doc/x.md matches files in doc/, not zh/doc/.
+//
+// A request for a default-language page is redirected (with status 302) to the
+// page's translation into the reader's preferred language, if one exists.
+// The preferred language is the one recorded in the reader's “lang” cookie,
+// or else the best match for the request's Accept-Language header.
+// Any URL with a “?lang=zh” query parameter sets the cookie and redirects to
+// the same URL without the parameter or any language prefix, allowing readers
+// to switch languages explicitly.
+// A request for a translation that does not exist, like /zh/doc/y,
+// is redirected to the default-language version, /doc/y.
+//
+// Compression
+//
+// Responses with textual content types (text/*, JavaScript, JSON, and XML)
+// are compressed with gzip when the request's Accept-Encoding header allows it,
+// except for responses known to be shorter than 256 bytes, partial content
+// responses, and responses that already have a Content-Encoding.
+// Compressed responses carry a weak form of the page's ETag.
+//
+// For a raw file f, a sibling file f.br or f.gz in fsys holds a precompressed
+// copy of f, as produced by “brotli f” or “gzip -k f”. When the request accepts
+// the corresponding encoding, the Site serves the precompressed copy, preferring
+// Brotli to gzip, with the content type of f.
+// Brotli responses are only available in this way: there is no on-the-fly
+// Brotli compression.
+//
 // Serving Dynamic Requests
 //
 // Of course, a web site may wish to serve more than static content.
@@ -286,8 +624,20 @@
 //		"error": err,
 //	}
 //
-// If that rendering itself fails, the Site responds with status 500
-// and the cryptic page text “error rendering error”.
+// The status is 404 (not found) instead if there is no such file.
+// The layout is specific to the status, such as “404” or “500”,
+// if a template like 404.tmpl is found by the usual search for layouts,
+// starting in the URL's directory, so that a subtree can have its own
+// error pages. Otherwise it is the generic “error” layout.
+//
+// For a 404 error, if the site has a page whose URL is close to the
+// requested one, such as /doc/install for /doc/instal or /doc/tutorial
+// for a page that moved from /tutorial, the Page also has a key
+// “suggestion” holding that page's URL, for a “did you mean” link.
+//
+// If the rendering of the error page itself fails, the Site logs the problem
+// and responds with a small static HTML page giving the status, which
+// does not use any site templates.
 //
 // The Site.ServeError and Site.ServeErrorStatus methods provide a way
 // for dynamic servers to generate similar responses.
@@ -307,6 +657,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/goplus/website/internal/backport/html/template"
 	"github.com/goplus/website/internal/spec"
@@ -316,18 +667,31 @@ import (
 // A Site is an http.Handler that serves requests from a file system.
 // See the package doc comment for details.
 type Site struct {
-	fs         fs.FS            // from NewSite
-	fileServer http.Handler     // http.FileServer(http.FS(fs))
-	funcs      template.FuncMap // accumulated from s.Funcs
-	cache      sync.Map         // canonical file path -> *pageFile, for site.openPage
+	fs          fs.FS            // from NewSite
+	fileServer  http.Handler     // http.FileServer(http.FS(fs))
+	funcs       template.FuncMap // accumulated from s.Funcs
+	cache       sync.Map         // canonical file path -> *pageFile, for site.openPage
+	aliases     aliasIndex       // old URLs from page "aliases" metadata
+	output      outputCache      // rendered pages, for serveCachedPage
+	assets      sync.Map         // asset file path -> *assetHash, for site.assetHash
+	langs       []string         // content languages, default first; from s.SetLanguages
+	schema      schemaCache      // metadata schema, for site.metaSchema
+	collections sync.Map         // collection directory -> *collection, for site.collection
+
+	renderTimeout time.Duration // from s.SetRenderLimits
+	renderSize    int64         // from s.SetRenderLimits
 }
 
 // NewSite returns a new Site for serving pages from the file system fsys.
 func NewSite(fsys fs.FS) *Site {
-	return &Site{
+	s := &Site{
 		fs:         fsys,
 		fileServer: http.FileServer(http.FS(fsys)),
 	}
+	s.output.max = defaultOutputCacheSize
+	s.renderTimeout = defaultRenderTimeout
+	s.renderSize = defaultRenderSize
+	return s
 }
 
 // Funcs adds the functions in m to the set of functions available to templates.
@@ -373,26 +737,33 @@ func (s *Site) ServeError(w http.ResponseWriter, r *http.Request, err error) {
 //		"error": err,
 //	}
 //
+// except that the layout and suggestion are chosen as described
+// in the “Serving Errors” section of the package doc comment.
 func (s *Site) ServeErrorStatus(w http.ResponseWriter, r *http.Request, err error, status int) {
 	s.serveErrorStatus(w, r, err, status, false)
 }
 
 func (s *Site) serveErrorStatus(w http.ResponseWriter, r *http.Request, err error, status int, renderingError bool) {
+	traceOf(r).setClass(ClassError)
 
 	if renderingError {
 		log.Printf("error rendering error: %v", err)
-		w.WriteHeader(status)
-		w.Write([]byte("error rendering error"))
+		serveFallbackError(w, status)
 		return
 	}
 
 	p := Page{
 		"URL":    r.URL.Path,
 		"status": status,
-		"layout": "error",
+		"layout": s.errorLayout(r.URL.Path, status),
 		"error":  err,
 	}
-	s.servePage(w, r, p, true)
+	if status == http.StatusNotFound {
+		if u := s.suggestPage(r.URL.Path); u != "" {
+			p["suggestion"] = u
+		}
+	}
+	s.servePage(w, r, p, nil, true)
 }
 
 // ServePage renders the page p to HTML and writes that HTML to w.
@@ -401,17 +772,48 @@ func (s *Site) serveErrorStatus(w http.ResponseWriter, r *http.Request, err erro
 // So that all templates can assume the presence of p["URL"],
 // if p["URL"] is unset or does not have type string, then ServePage
 // sets p["URL"] to r.URL.Path in a clone of p before rendering the page.
+//
+// ServePage sets no Last-Modified header, since it cannot know
+// all the inputs from which p was made.
 func (s *Site) ServePage(w http.ResponseWriter, r *http.Request, p Page) {
-	s.servePage(w, r, p, false)
+	s.servePage(w, r, p, nil, false)
 }
 
-func (s *Site) servePage(w http.ResponseWriter, r *http.Request, p Page, renderingError bool) {
-	html, err := s.renderHTML(p, "site.tmpl", r)
+// servePage is like ServePage, but if deps is non-nil, it holds the inputs
+// already read to make p, and servePage records the files the rendering reads
+// in it and uses them to set the Last-Modified header.
+func (s *Site) servePage(w http.ResponseWriter, r *http.Request, p Page, deps *depRecorder, renderingError bool) {
+	html, err := s.renderHTML(p, "site.tmpl", r, deps)
 	if err != nil {
 		s.serveErrorStatus(w, r, fmt.Errorf("template execution: %v", err), http.StatusInternalServerError, renderingError)
 		return
 	}
-	if code, ok := p["status"].(int); ok {
+	if renderingError {
+		s.writePage(w, r, p, html, time.Time{}, false)
+		return
+	}
+	s.writePage(w, r, p, html, deps.modTime(), true)
+}
+
+// writePage writes html, the rendering of the page p, to w.
+// If cacheable is true and p has no explicit non-200 status,
+// writePage sets the caching headers, using modtime as the
+// last modification time, and checks for a conditional request
+// that can be answered with 304 (not modified).
+func (s *Site) writePage(w http.ResponseWriter, r *http.Request, p Page, html []byte, modtime time.Time, cacheable bool) {
+	if t := traceOf(r); t != nil {
+		if t.Class == "" {
+			t.Class = ClassPage
+		}
+		if timing := t.serverTiming(); timing != "" {
+			w.Header().Set("Server-Timing", timing)
+		}
+	}
+	code, ok := p["status"].(int)
+	if (!ok || code == http.StatusOK) && cacheable && s.checkNotModified(w, r, p, html, modtime) {
+		return
+	}
+	if ok {
 		w.WriteHeader(code)
 	}
 	w.Write(html)
@@ -420,9 +822,18 @@ func (s *Site) servePage(w http.ResponseWriter, r *http.Request, p Page, renderi
 // ServeHTTP implements http.Handler, serving from a file in the site.
 // See the Site type documentation for details about how requests are handled.
 func (s *Site) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	gw := newGzipResponseWriter(w, r)
+	defer gw.close()
+	w = gw
+
 	abspath := r.URL.Path
 	relpath := path.Clean(strings.TrimPrefix(abspath, "/"))
 
+	// Is it a choice of language?
+	if s.serveLangChoice(w, r, relpath) {
+		return
+	}
+
 	// Is it a page we can generate?
 	if p, err := s.openPage(relpath); err == nil {
 		if p.url != abspath {
@@ -434,7 +845,12 @@ func (s *Site) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			http.Redirect(w, r, p.url, status)
 			return
 		}
+		// Does the reader prefer a translation?
+		if s.negotiateLang(w, r, relpath, p) {
+			return
+		}
 		// Serve from the actual filesystem path.
+		traceOf(r).setClass(ClassPage)
 		s.serveHTML(w, r, p)
 		return
 	}
@@ -444,6 +860,25 @@ func (s *Site) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		status := http.StatusInternalServerError
 		if errors.Is(err, fs.ErrNotExist) {
+			// Is it a fingerprinted asset URL?
+			if s.serveAsset(w, r, relpath) {
+				return
+			}
+			// Is it a translation that does not exist (yet)?
+			if s.serveLangFallback(w, r, relpath) {
+				return
+			}
+			// Is it a numbered page of a paginated listing?
+			if s.servePageNumber(w, r, relpath) {
+				return
+			}
+			// Is it an old URL of a page that has moved?
+			if u, ok := s.lookupAlias(relpath); ok {
+				url := *r.URL
+				url.Path = u
+				http.Redirect(w, r, url.String(), http.StatusMovedPermanently)
+				return
+			}
 			status = http.StatusNotFound
 		}
 		s.ServeErrorStatus(w, r, err, status)
@@ -452,8 +887,9 @@ func (s *Site) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// Serve directory.
 	if info != nil && info.IsDir() {
-		if _, ok := s.findLayout(relpath, "dir"); ok {
+		if _, ok := s.findLayout(relpath, "dir", nil); ok {
 			if !maybeRedirect(w, r) {
+				traceOf(r).setClass(ClassDir)
 				s.serveDir(w, r, relpath)
 			}
 			return
@@ -462,15 +898,23 @@ func (s *Site) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// Serve text file.
 	if isTextFile(s.fs, relpath) {
-		if _, ok := s.findLayout(path.Dir(relpath), "text"); ok {
+		if _, ok := s.findLayout(path.Dir(relpath), "text", nil); ok {
 			if !maybeRedirectFile(w, r) {
+				traceOf(r).setClass(ClassText)
 				s.serveText(w, r, relpath)
 			}
 			return
 		}
 	}
 
-	// Serve raw bytes.
+	// Serve raw bytes, preferring a precompressed copy.
+	traceOf(r).setClass(ClassFile)
+	if info != nil && !info.IsDir() && hasPrecompressed(s.fs, relpath) {
+		if s.servePrecompressed(w, r, relpath) {
+			return
+		}
+		addVary(w.Header())
+	}
 	s.fileServer.ServeHTTP(w, r)
 }
 
@@ -505,9 +949,24 @@ func (s *Site) serveHTML(w http.ResponseWriter, r *http.Request, p *pageFile) {
 	filePath, _ := p.page["File"].(string)
 	isMarkdown := strings.HasSuffix(filePath, ".md")
 
+	// The same URL serves HTML or JSON, depending on the Accept header.
+	w.Header().Add("Vary", "Accept")
+
 	// if it begins with "<!DOCTYPE " assume it is standalone
 	// html that doesn't need the template wrapping.
 	if strings.HasPrefix(src, "<!DOCTYPE ") {
+		if wantsJSON(r) {
+			jp := make(Page)
+			for k, v := range p.page {
+				jp[k] = v
+			}
+			jp["Content"] = template.HTML(src)
+			s.servePageJSON(w, r, jp)
+			return
+		}
+		if s.checkNotModified(w, r, p.page, []byte(src), p.stat.ModTime()) {
+			return
+		}
 		w.Write([]byte(src))
 		return
 	}
@@ -525,7 +984,11 @@ func (s *Site) serveHTML(w http.ResponseWriter, r *http.Request, p *pageFile) {
 	if !isTemplate && !isMarkdown {
 		p.page["Content"] = template.HTML(src)
 	}
-	s.ServePage(w, r, p.page)
+	if wantsJSON(r) {
+		s.servePageJSON(w, r, p.page)
+		return
+	}
+	s.serveCachedPage(w, r, p)
 }
 
 func (s *Site) serveDir(w http.ResponseWriter, r *http.Request, relpath string) {
@@ -556,6 +1019,8 @@ func (s *Site) serveDir(w http.ResponseWriter, r *http.Request, relpath string)
 }
 
 func (s *Site) serveText(w http.ResponseWriter, r *http.Request, relpath string) {
+	deps := newDepRecorder()
+	deps.stat(s.fs, relpath)
 	src, err := fs.ReadFile(s.fs, relpath)
 	if err != nil {
 		log.Printf("ReadFile: %s", err)
@@ -582,12 +1047,12 @@ func (s *Site) serveText(w http.ResponseWriter, r *http.Request, relpath string)
 
 	fmt.Fprintf(&buf, `<p><a href="/%s?m=text">View as plain text</a></p>`, html.EscapeString(relpath))
 
-	s.ServePage(w, r, Page{
+	s.servePage(w, r, Page{
 		"URL":      r.URL.Path,
 		"File":     relpath,
 		"layout":   "texthtml",
 		"texthtml": template.HTML(buf.String()),
-	})
+	}, deps, false)
 }
 
 var selRx = regexp.MustCompile(`^([0-9]+):([0-9]+)`)
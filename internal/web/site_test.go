@@ -5,12 +5,23 @@
 package web
 
 import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"regexp"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"testing/fstest"
+	"time"
+
+	"github.com/goplus/website/internal/backport/html/template"
 )
 
 func testServeBody(t *testing.T, p *Site, path, body string) {
@@ -58,3 +69,1058 @@ func TestMarkdown(t *testing.T) {
 	testServeBody(t, site, "/doc/test", "<strong>bold</strong>")
 	testServeBody(t, site, "/doc/test2", "<em>template</em>")
 }
+
+func TestMarkdownExtensions(t *testing.T) {
+	md := "!!! warning\n" +
+		"    Careful *now*.\n" +
+		"\n" +
+		"!!! tip \"Pro tip\"\n" +
+		"    - a\n" +
+		"\n" +
+		"!!! note \"\"\n" +
+		"    Untitled.\n" +
+		"\n" +
+		"Outside.\n" +
+		"\n" +
+		"=== \"Go\"\n" +
+		"    Go code.\n" +
+		"\n" +
+		"=== \"Go+\"\n" +
+		"    Go+ code.\n" +
+		"\n" +
+		"Footnote[^1].\n" +
+		"\n" +
+		"Term\n" +
+		": Definition\n" +
+		"\n" +
+		"[^1]: The note.\n"
+	html, err := markdownToHTML(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<div class=\"Admonition Admonition--warning\">\n<p class=\"Admonition-title\">Warning</p>\n<p>Careful <em>now</em>.</p>\n</div>\n",
+		"<div class=\"Admonition Admonition--tip\">\n<p class=\"Admonition-title\">Pro tip</p>\n<ul>\n<li>a</li>\n</ul>\n</div>\n",
+		"<div class=\"Admonition Admonition--note\">\n<p>Untitled.</p>\n</div>\n<p>Outside.</p>\n",
+		"<div class=\"TabSection js-tabs\">\n" +
+			"<div class=\"TabSection-tabList\" role=\"tablist\">\n" +
+			"<button role=\"tab\" aria-selected=\"true\" tabindex=\"0\" class=\"TabSection-tab\">Go</button>\n" +
+			"<button role=\"tab\" aria-selected=\"false\" tabindex=\"-1\" class=\"TabSection-tab\">Go+</button>\n" +
+			"</div>\n" +
+			"<div role=\"tabpanel\" class=\"TabSection-tabPanel\">\n<p>Go code.</p>\n</div>\n" +
+			"<div role=\"tabpanel\" class=\"TabSection-tabPanel\" hidden>\n<p>Go+ code.</p>\n</div>\n" +
+			"</div>\n",
+		`<a href="#fn:1" class="footnote-ref" role="doc-noteref">1</a>`,
+		`<section class="footnotes" role="doc-endnotes">`,
+		"<dl>\n<dt>Term</dt>\n<dd>Definition</dd>\n</dl>\n",
+	} {
+		if !strings.Contains(string(html), want) {
+			t.Errorf("markdownToHTML missing:\n%s\nhave:\n%s", want, html)
+		}
+	}
+}
+
+func TestMarkdownCode(t *testing.T) {
+	md := "```go linenos=10 hl_lines=2,4-5\n" +
+		"package main\n" +
+		"\n" +
+		"// Say <hello>.\n" +
+		"func main() {\n" +
+		"}\n" +
+		"```\n" +
+		"\n" +
+		"```go play\n" +
+		"println(\"hi\")\n" +
+		"```\n" +
+		"\n" +
+		"```gop play\n" +
+		"println \"hi\"\n" +
+		"```\n" +
+		"\n" +
+		"```sh\n" +
+		"$ gop run <dir>\n" +
+		"```\n"
+	html, err := markdownToHTML(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<div class=\"code language-go\"><pre>" +
+			"<span id=\"L10\" class=\"ln\">    10&nbsp;&nbsp;</span>package main\n" +
+			"<span id=\"L11\" class=\"ln\">    11&nbsp;&nbsp;</span>\n" +
+			"<span id=\"L12\" class=\"ln\">    12&nbsp;&nbsp;</span><span class=\"comment\">// Say &lt;hello&gt;.</span>\n" +
+			"<span id=\"L13\" class=\"ln\">    13&nbsp;&nbsp;</span><span class=\"selection\">func main() {</span>\n" +
+			"<span id=\"L14\" class=\"ln\">    14&nbsp;&nbsp;</span><span class=\"selection\">}</span>\n" +
+			"</pre></div>\n",
+		"<div class=\"play language-go\">\n" +
+			"<div class=\"input\"><textarea class=\"code\" spellcheck=\"false\">println(&#34;hi&#34;)\n</textarea></div>\n",
+		"<div class=\"play language-gop\" data-playground=\"https://play.goplus.org\">\n" +
+			"<div class=\"input\"><textarea class=\"code\" spellcheck=\"false\">println &#34;hi&#34;\n</textarea></div>\n",
+		"<pre><code class=\"language-sh\">$ gop run &lt;dir&gt;\n</code></pre>\n",
+	} {
+		if !strings.Contains(string(html), want) {
+			t.Errorf("markdownToHTML missing:\n%s\nhave:\n%s", want, html)
+		}
+	}
+
+	// A huge range costs no more than the block's lines.
+	md = "```go hl_lines=2-1000000000\na\nb\n```\n"
+	want := "<div class=\"code language-go\"><pre>a\n<span class=\"selection\">b</span>\n</pre></div>\n"
+	if html, err := markdownToHTML(md); err != nil || string(html) != want {
+		t.Errorf("markdownToHTML(%q) = %q, %v, want %q", md, html, err, want)
+	}
+
+	// Invalid attributes fall back to plain rendering.
+	for _, md := range []string{
+		"```go lineno\nx<y\n```\n",
+		"```go hl_lines=5-3\nx<y\n```\n",
+		"```go linenos=x\nx<y\n```\n",
+	} {
+		lang := strings.Fields(md[3:])[0]
+		want := "<pre><code class=\"language-" + lang + "\">x&lt;y\n</code></pre>\n"
+		if html, err := markdownToHTML(md); err != nil || string(html) != want {
+			t.Errorf("markdownToHTML(%q) = %q, %v, want %q", md, html, err, want)
+		}
+	}
+}
+
+func TestAliases(t *testing.T) {
+	site := NewSite(fstest.MapFS{
+		"site.tmpl":       {Data: []byte(`{{.Content}}`)},
+		"doc/new.md":      {Data: []byte("---\naliases:\n  - /doc/old\n  - older.html\n  - /doc/here\n---\nNew page.")},
+		"doc/here.md":     {Data: []byte("Here.")},
+		"doc/x/index.md":  {Data: []byte("---\naliases: /doc/y/\n---\nX.")},
+		"doc/z.md":        {Data: []byte("---\naliases: [/doc/y]\n---\nZ.")},
+		"doc/file.txt":    {Data: []byte("text")},
+		"doc/text.md":     {Data: []byte("---\naliases: [file.txt]\n---\nText.")},
+		"doc/notalias.md": {Data: []byte("Not an alias.")},
+	})
+
+	for _, tt := range []struct{ path, loc string }{
+		{"/doc/old", "/doc/new"},
+		{"/doc/old.html", "/doc/new"},
+		{"/doc/older", "/doc/new"},
+		{"/doc/old?x=1", "/doc/new?x=1"},
+	} {
+		r := httptest.NewRequest("GET", tt.path, nil)
+		rw := httptest.NewRecorder()
+		site.ServeHTTP(rw, r)
+		if loc := rw.Header().Get("Location"); rw.Code != 301 || loc != tt.loc {
+			t.Errorf("GET %s: got %d -> %q, want 301 -> %q", tt.path, rw.Code, loc, tt.loc)
+		}
+	}
+
+	// Aliases never hide real pages or files,
+	// and an alias claimed twice redirects nowhere.
+	testServeBody(t, site, "/doc/here", "Here.")
+	for _, path := range []string{"/doc/y", "/doc/y/"} {
+		r := httptest.NewRequest("GET", path, nil)
+		rw := httptest.NewRecorder()
+		site.ServeHTTP(rw, r)
+		if rw.Code == 301 {
+			t.Errorf("GET %s: got redirect to %q, want none", path, rw.Header().Get("Location"))
+		}
+	}
+
+	errs := site.CheckAliases()
+	var msgs []string
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	want := []string{
+		"doc/new.md: alias /doc/here conflicts with page doc/here.md",
+		"doc/text.md: alias /doc/file.txt conflicts with file doc/file.txt",
+		"doc/z.md: alias /doc/y already claimed by doc/x/index.md",
+	}
+	if strings.Join(msgs, "\n") != strings.Join(want, "\n") {
+		t.Errorf("CheckAliases:\n%s\nwant:\n%s", strings.Join(msgs, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestAliasRefresh(t *testing.T) {
+	fsys := fstest.MapFS{
+		"site.tmpl":  {Data: []byte(`{{.Content}}`)},
+		"doc/new.md": {Data: []byte("---\naliases: [/doc/old]\n---\nNew page.")},
+	}
+	site := NewSite(fsys)
+	site.CheckAliases()
+	get := func(path string) string {
+		rw := httptest.NewRecorder()
+		site.ServeHTTP(rw, httptest.NewRequest("GET", path, nil))
+		return rw.Header().Get("Location")
+	}
+	// expire makes the next lookup start a background check
+	// and returns the index being checked.
+	expire := func() *aliases {
+		x := &site.aliases
+		x.mu.Lock()
+		defer x.mu.Unlock()
+		x.checked = time.Time{}
+		return x.cur
+	}
+	// wait waits for the background check to finish.
+	wait := func() {
+		for i := 0; ; i++ {
+			x := &site.aliases
+			x.mu.Lock()
+			done := !x.checking
+			x.mu.Unlock()
+			if done {
+				return
+			}
+			if i == 1000 {
+				t.Fatal("background alias check did not finish")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	if loc := get("/doc/old"); loc != "/doc/new" {
+		t.Fatalf("GET /doc/old: Location %q", loc)
+	}
+
+	// Unchanged pages keep the same index.
+	old := expire()
+	get("/doc/nope")
+	wait()
+	if site.aliases.cur != old {
+		t.Errorf("alias index rebuilt with no page changes")
+	}
+
+	// A new alias is picked up in the background.
+	fsys["doc/other.md"] = &fstest.MapFile{Data: []byte("---\naliases: [/doc/gone]\n---\nOther.")}
+	expire()
+	if loc := get("/doc/gone"); loc != "" {
+		t.Errorf("GET /doc/gone: redirected to %q before rebuild finished", loc)
+	}
+	wait()
+	if loc := get("/doc/gone"); loc != "/doc/other" {
+		t.Errorf("GET /doc/gone after rebuild: Location %q, want /doc/other", loc)
+	}
+}
+
+func TestConditionalGet(t *testing.T) {
+	mtime := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	site := NewSite(fstest.MapFS{
+		"site.tmpl":         {Data: []byte(`{{.Content}}`), ModTime: mtime.Add(-time.Hour)},
+		"dir.tmpl":          {Data: []byte(`{{define "layout"}}dir{{end}}`)},
+		"doc/index.md":      {Data: []byte("---\ncache-control: public, max-age=60\n---\nDocs.")},
+		"doc/page.md":       {Data: []byte("Page."), ModTime: mtime},
+		"doc/own.md":        {Data: []byte("---\ncache-control: no-cache\n---\nOwn.")},
+		"doc/sub/x.txt":     {Data: []byte("x")},
+		"other/page.md":     {Data: []byte("Other."), ModTime: mtime},
+		"doc/standalone.md": {Data: []byte("<!DOCTYPE html>\nhello")},
+		"doc/incl.md":       {Data: []byte(`{{file "x.txt"}}`), ModTime: mtime},
+		"doc/x.txt":         {Data: []byte("x"), ModTime: mtime.Add(time.Hour)},
+		"doc/list.md":       {Data: []byte(`{{len (pages "*.md")}}`), ModTime: mtime},
+	})
+
+	get := func(path string, hdr ...string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest("GET", path, nil)
+		for i := 0; i+1 < len(hdr); i += 2 {
+			r.Header.Set(hdr[i], hdr[i+1])
+		}
+		rw := httptest.NewRecorder()
+		site.ServeHTTP(rw, r)
+		return rw
+	}
+
+	rw := get("/doc/page")
+	etag := rw.Header().Get("ETag")
+	if rw.Code != 200 || etag == "" {
+		t.Fatalf("GET /doc/page: %d, ETag %q", rw.Code, etag)
+	}
+	if lm := rw.Header().Get("Last-Modified"); lm != mtime.Format(http.TimeFormat) {
+		t.Errorf("Last-Modified = %q, want %q", lm, mtime.Format(http.TimeFormat))
+	}
+	if cc := rw.Header().Get("Cache-Control"); cc != "public, max-age=60" {
+		t.Errorf("inherited Cache-Control = %q", cc)
+	}
+
+	for _, tt := range []struct {
+		hdr  []string
+		code int
+	}{
+		{[]string{"If-None-Match", etag}, 304},
+		{[]string{"If-None-Match", `"other", W/` + etag}, 304},
+		{[]string{"If-None-Match", `"other"`}, 200},
+		{[]string{"If-Modified-Since", mtime.Format(http.TimeFormat)}, 304},
+		{[]string{"If-Modified-Since", mtime.Add(-time.Second).Format(http.TimeFormat)}, 200},
+		{[]string{"If-None-Match", `"other"`, "If-Modified-Since", mtime.Format(http.TimeFormat)}, 200},
+	} {
+		rw := get("/doc/page", tt.hdr...)
+		if rw.Code != tt.code {
+			t.Errorf("GET /doc/page %q: %d, want %d", tt.hdr, rw.Code, tt.code)
+		}
+		if rw.Code == 304 && rw.Body.Len() != 0 {
+			t.Errorf("GET /doc/page %q: 304 with body", tt.hdr)
+		}
+	}
+
+	if cc := get("/doc/own").Header().Get("Cache-Control"); cc != "no-cache" {
+		t.Errorf("own Cache-Control = %q", cc)
+	}
+	if cc := get("/doc/sub/").Header().Get("Cache-Control"); cc != "public, max-age=60" {
+		t.Errorf("dir Cache-Control = %q", cc)
+	}
+	if cc := get("/other/page").Header().Get("Cache-Control"); cc != "" {
+		t.Errorf("unrelated Cache-Control = %q", cc)
+	}
+	rw = get("/doc/standalone")
+	if rw = get("/doc/standalone", "If-None-Match", rw.Header().Get("ETag")); rw.Code != 304 {
+		t.Errorf("standalone page: %d, want 304", rw.Code)
+	}
+
+	// Last-Modified covers files read by template functions,
+	// and is omitted when the page depends on a glob.
+	later := mtime.Add(time.Hour).Format(http.TimeFormat)
+	for _, tt := range []struct{ path, accept, lm string }{
+		{"/doc/incl", "", later},
+		{"/doc/incl", "application/json", later},
+		{"/doc/x.txt", "", later},
+		{"/doc/list", "", ""},
+		{"/doc/list", "application/json", ""},
+	} {
+		rw := get(tt.path, "Accept", tt.accept)
+		if lm := rw.Header().Get("Last-Modified"); rw.Code != 200 || lm != tt.lm {
+			t.Errorf("GET %s (Accept: %q): %d, Last-Modified = %q, want %q", tt.path, tt.accept, rw.Code, lm, tt.lm)
+		}
+	}
+}
+
+func TestOutputCache(t *testing.T) {
+	mtime := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	fsys := fstest.MapFS{
+		"site.tmpl":        {Data: []byte(`{{block "layout" .}}{{.Content}}{{end}}{{count}}`), ModTime: mtime},
+		"doc/default.tmpl": {Data: []byte(`{{define "layout"}}[{{.Content}}]{{end}}`), ModTime: mtime},
+		"doc/page.md":      {Data: []byte(`{{file "x.txt"}} {{len (pages "sub/*")}}`), ModTime: mtime},
+		"doc/x.txt":        {Data: []byte("x1"), ModTime: mtime},
+		"doc/sub/a.md":     {Data: []byte("a"), ModTime: mtime},
+		"doc/req.md":       {Data: []byte(`{{(request).URL.RawQuery}}`), ModTime: mtime},
+	}
+	site := NewSite(fsys)
+	var renders int
+	site.Funcs(map[string]interface{}{
+		"count": func() int { renders++; return renders },
+	})
+
+	get := func(path string) string {
+		t.Helper()
+		rw := httptest.NewRecorder()
+		site.ServeHTTP(rw, httptest.NewRequest("GET", path, nil))
+		if rw.Code != 200 {
+			t.Fatalf("GET %s: %d %s", path, rw.Code, rw.Body.String())
+		}
+		return strings.ReplaceAll(rw.Body.String(), "\n", "")
+	}
+	check := func(path, want string) {
+		t.Helper()
+		if got := get(path); got != want {
+			t.Errorf("GET %s = %q, want %q", path, got, want)
+		}
+	}
+	touch := func(name, data string) {
+		mtime = mtime.Add(time.Second)
+		fsys[name] = &fstest.MapFile{Data: []byte(data), ModTime: mtime}
+	}
+
+	check("/doc/page", "[<p>x1 1</p>]1")
+	check("/doc/page", "[<p>x1 1</p>]1") // cached
+	check("/doc/page?q=1", "[<p>x1 1</p>]2")
+
+	touch("doc/x.txt", "x2") // {{file}} changed
+	check("/doc/page", "[<p>x2 1</p>]3")
+	check("/doc/page", "[<p>x2 1</p>]3")
+
+	touch("doc/sub/b.md", "b") // {{pages}} matches changed
+	check("/doc/page", "[<p>x2 2</p>]4")
+
+	touch("doc/default.tmpl", `{{define "layout"}}({{.Content}}){{end}}`) // layout changed
+	check("/doc/page", "(<p>x2 2</p>)5")
+
+	touch("doc/page.default.tmpl", "") // unrelated file
+	check("/doc/page", "(<p>x2 2</p>)5")
+
+	touch("default.tmpl", "") // shadowed layout
+	check("/doc/page", "(<p>x2 2</p>)5")
+
+	check("/doc/req?a", "(<p>a</p>)6") // uses {{request}}: not cached
+	check("/doc/req?a", "(<p>a</p>)7")
+
+	site.SetOutputCacheSize(1) // too small for any page
+	check("/doc/page", "(<p>x2 2</p>)8")
+	check("/doc/page", "(<p>x2 2</p>)9")
+	if site.output.size != 0 {
+		t.Errorf("output cache size = %d, want 0", site.output.size)
+	}
+}
+
+func TestCompression(t *testing.T) {
+	big := strings.Repeat("All work and no play makes Jack a dull boy.\n", 100)
+	site := NewSite(fstest.MapFS{
+		"site.tmpl":        {Data: []byte(`{{.Content}}`)},
+		"doc/page.md":      {Data: []byte(big)},
+		"doc/small.md":     {Data: []byte("small")},
+		"lib/app.js":       {Data: []byte(big)},
+		"lib/app.js.br":    {Data: []byte("brotli!")},
+		"lib/app.js.gz":    {Data: []byte("gzip!")},
+		"lib/style.css":    {Data: []byte(big)},
+		"lib/image.png":    {Data: []byte("\x89PNG\r\n\x1a\n" + big)},
+		"lib/tiny.css":     {Data: []byte("body {}")},
+		"lib/wasm.wasm":    {Data: []byte("\x00asm" + big)},
+		"lib/wasm.wasm.gz": {Data: []byte("gzip wasm")},
+	})
+
+	get := func(path, accept string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest("GET", path, nil)
+		if accept != "" {
+			r.Header.Set("Accept-Encoding", accept)
+		}
+		rw := httptest.NewRecorder()
+		site.ServeHTTP(rw, r)
+		if rw.Code != 200 {
+			t.Fatalf("GET %s: %d", path, rw.Code)
+		}
+		return rw
+	}
+	gunzip := func(rw *httptest.ResponseRecorder) string {
+		t.Helper()
+		zr, err := gzip.NewReader(rw.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	for _, tt := range []struct {
+		path, accept, enc, ctype, body string
+	}{
+		{"/doc/page", "gzip, deflate, br", "gzip", "text/html", ""},
+		{"/doc/page", "", "", "text/html", ""},
+		{"/doc/page", "gzip;q=0, *", "", "text/html", ""},
+		{"/doc/small", "gzip", "gzip", "text/html", ""},
+		{"/lib/style.css", "gzip", "gzip", "text/css", ""},
+		{"/lib/tiny.css", "gzip", "", "text/css", "body {}"},
+		{"/lib/image.png", "gzip", "", "image/png", ""},
+		{"/lib/app.js", "gzip, br", "br", "javascript", "brotli!"},
+		{"/lib/app.js", "gzip", "gzip", "javascript", "gzip!"},
+		{"/lib/app.js", "identity", "", "javascript", big},
+		{"/lib/wasm.wasm", "gzip", "gzip", "application/wasm", "gzip wasm"},
+	} {
+		rw := get(tt.path, tt.accept)
+		h := rw.Header()
+		if enc := h.Get("Content-Encoding"); enc != tt.enc {
+			t.Errorf("GET %s (%q): Content-Encoding = %q, want %q", tt.path, tt.accept, enc, tt.enc)
+			continue
+		}
+		if ctype := h.Get("Content-Type"); !strings.Contains(ctype, tt.ctype) {
+			t.Errorf("GET %s (%q): Content-Type = %q, want %s", tt.path, tt.accept, ctype, tt.ctype)
+		}
+		if tt.ctype != "image/png" && !strings.Contains(strings.Join(h.Values("Vary"), ", "), "Accept-Encoding") {
+			t.Errorf("GET %s (%q): Vary = %q", tt.path, tt.accept, h.Values("Vary"))
+		}
+		if tt.body != "" && rw.Body.String() != tt.body {
+			t.Errorf("GET %s (%q): body = %.20q, want %.20q", tt.path, tt.accept, rw.Body.String(), tt.body)
+		}
+		if tt.enc == "gzip" && tt.body == "" {
+			if body := gunzip(rw); !strings.Contains(body, "All work") && !strings.Contains(body, "small") {
+				t.Errorf("GET %s (%q): gunzipped body = %.40q", tt.path, tt.accept, body)
+			}
+			if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+				t.Errorf("GET %s (%q): ETag = %q, want weak", tt.path, tt.accept, etag)
+			}
+		}
+	}
+}
+
+func TestAsset(t *testing.T) {
+	mtime := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	fsys := fstest.MapFS{
+		"site.tmpl":                 {Data: []byte(`{{asset "/lib/style.css"}} {{asset "../lib/LICENSE"}} {{.Content}}`)},
+		"error.tmpl":                {Data: []byte(`{{define "layout"}}{{.error}}{{end}}`)},
+		"doc/page.md":               {Data: []byte("Page.")},
+		"doc/missing.md":            {Data: []byte(`{{asset "/lib/missing.css"}}`)},
+		"lib/style.css":             {Data: []byte("body {}"), ModTime: mtime},
+		"lib/LICENSE":               {Data: []byte("license")},
+		"lib/other.css":             {Data: []byte("other")},
+		"lib/other.css.gz":          {Data: []byte("gzip")},
+		"lib/real.abcdef012345.css": {Data: []byte("real")},
+	}
+	site := NewSite(fsys)
+
+	get := func(path string, hdr ...string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest("GET", path, nil)
+		for i := 0; i+1 < len(hdr); i += 2 {
+			r.Header.Set(hdr[i], hdr[i+1])
+		}
+		rw := httptest.NewRecorder()
+		site.ServeHTTP(rw, r)
+		return rw
+	}
+
+	rw := get("/doc/page")
+	f := strings.Fields(rw.Body.String())
+	if rw.Code != 200 || len(f) < 2 {
+		t.Fatalf("GET /doc/page: %d %q", rw.Code, rw.Body.String())
+	}
+	css, license := f[0], f[1]
+	if !regexp.MustCompile(`^/lib/style\.[0-9a-f]{12}\.css$`).MatchString(css) {
+		t.Errorf("asset /lib/style.css = %q", css)
+	}
+	if !regexp.MustCompile(`^/lib/LICENSE\.[0-9a-f]{12}$`).MatchString(license) {
+		t.Errorf("asset ../lib/LICENSE = %q", license)
+	}
+	if rw := get("/doc/missing"); rw.Code != 500 {
+		t.Errorf("GET /doc/missing: %d, want 500", rw.Code)
+	}
+
+	for _, tt := range []struct {
+		path, body, cc string
+	}{
+		{css, "body {}", immutable},
+		{license, "license", immutable},
+		{"/lib/style.0123456789ab.css", "body {}", ""}, // stale hash
+		{"/lib/real.abcdef012345.css", "real", ""},     // real file
+	} {
+		rw := get(tt.path)
+		if rw.Code != 200 || rw.Body.String() != tt.body {
+			t.Errorf("GET %s: %d %q, want 200 %q", tt.path, rw.Code, rw.Body.String(), tt.body)
+		}
+		if cc := rw.Header().Get("Cache-Control"); cc != tt.cc {
+			t.Errorf("GET %s: Cache-Control = %q, want %q", tt.path, cc, tt.cc)
+		}
+	}
+
+	sum := sha256.Sum256([]byte("other"))
+	other := fmt.Sprintf("/lib/other.%x.css", sum[:6])
+	if rw := get(other, "Accept-Encoding", "gzip"); rw.Body.String() != "gzip" || rw.Header().Get("Content-Encoding") != "gzip" || rw.Header().Get("Cache-Control") != immutable {
+		t.Errorf("GET %s: %q %v", other, rw.Body.String(), rw.Header())
+	}
+	if rw := get("/lib/nothing.0123456789ab.css"); rw.Code != 404 {
+		t.Errorf("GET /lib/nothing.0123456789ab.css: %d, want 404", rw.Code)
+	}
+
+	// Changing the file changes its URL.
+	fsys["lib/style.css"] = &fstest.MapFile{Data: []byte("body { color: red }"), ModTime: mtime.Add(time.Second)}
+	if css2 := strings.Fields(get("/doc/page").Body.String())[0]; css2 == css {
+		t.Errorf("asset URL unchanged after edit: %s", css2)
+	}
+	if cc := get(css).Header().Get("Cache-Control"); cc != "" {
+		t.Errorf("GET old %s: Cache-Control = %q, want none", css, cc)
+	}
+}
+
+func TestLanguages(t *testing.T) {
+	site := NewSite(fstest.MapFS{
+		"site.tmpl":     {Data: []byte(`{{.Lang}} {{.URL}} {{range translations}}{{.Lang}}={{.Link}}{{if .Current}}*{{end}} {{end}}| {{.Content}}`)},
+		"error.tmpl":    {Data: []byte(`{{define "layout"}}{{.error}}{{end}}`)},
+		"index.md":      {Data: []byte("Home. {{range pages \"doc/*\"}}{{.Lang}}:{{.URL}} {{end}}")},
+		"index.zh.md":   {Data: []byte("主页. {{range pages \"doc/*\"}}{{.Lang}}:{{.URL}} {{end}}")},
+		"doc/a.md":      {Data: []byte("A.")},
+		"doc/a.zh.md":   {Data: []byte("甲.")},
+		"doc/b.md":      {Data: []byte("B.")},
+		"zh/doc/c.md":   {Data: []byte("丙.")},
+		"doc/c.md":      {Data: []byte("C.")},
+		"doc/image.png": {Data: []byte("PNG")},
+	})
+	site.SetLanguages("en", "zh")
+
+	serve := func(path string, hdr ...string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest("GET", path, nil)
+		for i := 0; i+1 < len(hdr); i += 2 {
+			r.Header.Set(hdr[i], hdr[i+1])
+		}
+		rw := httptest.NewRecorder()
+		site.ServeHTTP(rw, r)
+		return rw
+	}
+	for _, tt := range []struct {
+		path   string
+		hdr    []string
+		code   int
+		result string // body prefix or redirect target
+	}{
+		{"/doc/a", nil, 200, "en /doc/a en=/doc/a?lang=en* zh=/zh/doc/a?lang=zh | <p>A.</p>"},
+		{"/zh/doc/a", nil, 200, "zh /zh/doc/a en=/doc/a?lang=en zh=/zh/doc/a?lang=zh* | <p>甲.</p>"},
+		{"/zh/doc/c", nil, 200, "zh /zh/doc/c en=/doc/c?lang=en zh=/zh/doc/c?lang=zh* | <p>丙.</p>"},
+		{"/doc/a.zh", nil, 301, "/zh/doc/a"},
+		{"/doc/b", nil, 200, "en /doc/b en=/doc/b?lang=en* | <p>B.</p>"},
+		{"/zh/doc/b", nil, 302, "/doc/b"},
+		{"/zh/doc/b?x=1", nil, 302, "/doc/b?x=1"},
+		{"/zh/doc/image.png", nil, 302, "/doc/image.png"},
+		{"/zh/doc/missing", nil, 404, ""},
+		{"/", nil, 200, "en / en=/?lang=en* zh=/zh/?lang=zh | <p>Home. en:/doc/a en:/doc/b en:/doc/c</p>"},
+		{"/zh/", nil, 200, "zh /zh/ en=/?lang=en zh=/zh/?lang=zh* | <p>主页. zh:/zh/doc/a en:/doc/b zh:/zh/doc/c</p>"},
+
+		// Negotiation.
+		{"/doc/a", []string{"Accept-Language", "zh-CN,zh;q=0.9,en;q=0.8"}, 302, "/zh/doc/a"},
+		{"/doc/a?x=1", []string{"Accept-Language", "zh"}, 302, "/zh/doc/a?x=1"},
+		{"/doc/a", []string{"Accept-Language", "fr, en;q=0.9, zh;q=0.5"}, 200, "en /doc/a"},
+		{"/doc/a", []string{"Accept-Language", "zh;q=0, en"}, 200, "en /doc/a"},
+		{"/doc/b", []string{"Accept-Language", "zh"}, 200, "en /doc/b"},
+		{"/", []string{"Accept-Language", "zh"}, 302, "/zh/"},
+		{"/doc/a", []string{"Accept-Language", "zh", "Cookie", "lang=en"}, 200, "en /doc/a"},
+		{"/doc/a", []string{"Cookie", "lang=zh"}, 302, "/zh/doc/a"},
+		{"/doc/a", []string{"Cookie", "lang=xx"}, 200, "en /doc/a"},
+
+		// Explicit choice.
+		{"/zh/doc/a?lang=en", nil, 302, "/doc/a"},
+		{"/doc/a?lang=zh&x=1", nil, 302, "/doc/a?x=1"},
+		{"/doc/a?lang=xx", nil, 200, "en /doc/a"},
+	} {
+		rw := serve(tt.path, tt.hdr...)
+		if rw.Code != tt.code {
+			t.Errorf("GET %s %q: %d, want %d", tt.path, tt.hdr, rw.Code, tt.code)
+			continue
+		}
+		switch tt.code {
+		case 200:
+			if body := strings.TrimSpace(rw.Body.String()); !strings.HasPrefix(body, tt.result) {
+				t.Errorf("GET %s %q:\nhave %s\nwant %s", tt.path, tt.hdr, body, tt.result)
+			}
+		case 301, 302:
+			if loc := rw.Header().Get("Location"); loc != tt.result {
+				t.Errorf("GET %s %q: Location = %q, want %q", tt.path, tt.hdr, loc, tt.result)
+			}
+		}
+	}
+
+	rw := serve("/doc/a?lang=zh")
+	if c := rw.Result().Cookies(); len(c) != 1 || c[0].Name != "lang" || c[0].Value != "zh" || c[0].Path != "/" {
+		t.Errorf("GET /doc/a?lang=zh: cookies %v, want lang=zh", c)
+	}
+	if v := serve("/doc/b").Header().Values("Vary"); len(v) == 0 || !strings.Contains(v[0], "Accept-Language") {
+		t.Errorf("GET /doc/b: Vary = %q, want Accept-Language", v)
+	}
+}
+
+func TestCheckPages(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schema.yaml": {Data: []byte(`
+/:
+  title: string
+  layout: string
+  status: int
+  aliases: [string]
+  template: bool
+/blog/:
+  date: date
+  authors: [string]
+`)},
+		"index.md":       {Data: []byte("---\ntitle: Home\nlayuot: wide\n---\nHome.")},
+		"doc/a.md":       {Data: []byte("---\ntitle: A\nstatus: \"404\"\naliases: [/x, 1]\n---\nA.")},
+		"doc/b.html":     {Data: []byte("<!--{\n\t\"Title\": \"B\",\n\t\"Template\": true,\n\t\"Status\": 410,\n\t\"Date\": \"2021-01-02\"\n}-->\nB.")},
+		"doc/c.md":       {Data: []byte("No metadata.")},
+		"doc/code.md":    {Data: []byte("---\ntitle: Code\n---\n```go lineno\nx\n```\n\n````md\n```go bad\n````\n\n```gop play=x\nx\n```\n")},
+		"blog/post.md":   {Data: []byte("---\ntitle: Post\ndate: 2021-01-02\nauthors: [Gopher]\n---\nPost.")},
+		"blog/bad.md":    {Data: []byte("---\ntitle: Bad\ndate: yesterday\n---\nBad.")},
+		"blog/broken.md": {Data: []byte("---\ntitle: [\n---\nBroken.")},
+	}
+	site := NewSite(fsys)
+	var list []string
+	for _, err := range site.CheckPages() {
+		list = append(list, err.Error())
+	}
+	want := []string{
+		`blog/bad.md:3: metadata key "date" has type string, want date`,
+		`blog/broken.md: parsing metadata: yaml: line 1: did not find expected node content`,
+		`doc/a.md:3: metadata key "status" has type string, want int`,
+		`doc/a.md:4: metadata key "aliases" has type list, want [string]`,
+		`doc/b.html:5: unknown metadata key "date"`,
+		"doc/code.md:4: ```go lineno: unknown code block attribute \"lineno\"",
+		"doc/code.md:12: ```gop play=x: invalid play=x",
+		`index.md:3: unknown metadata key "layuot" (did you mean "layout"?)`,
+	}
+	if got := strings.Join(list, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("CheckPages:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+	}
+
+	fsys["schema.yaml"] = &fstest.MapFile{Data: []byte("doc:\n  title: strin\n")}
+	errs := site.CheckPages()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), `directory "doc" does not begin with /`) {
+		t.Errorf("CheckPages with bad schema: %v", errs)
+	}
+	fsys["schema.yaml"] = &fstest.MapFile{Data: []byte("/:\n  title: strin\n")}
+	errs = site.CheckPages()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), `key "title" has unknown type strin`) {
+		t.Errorf("CheckPages with bad schema: %v", errs)
+	}
+}
+
+func TestErrorPages(t *testing.T) {
+	fsys := fstest.MapFS{
+		"site.tmpl":        {Data: []byte(`{{.layout}}: {{block "layout" .}}{{.Content}}{{end}}`)},
+		"error.tmpl":       {Data: []byte(`{{define "layout"}}{{.status}} {{.error}}{{end}}`)},
+		"404.tmpl":         {Data: []byte(`{{define "layout"}}not found{{with .suggestion}}; did you mean {{.}}{{end}}{{end}}`)},
+		"doc/410.tmpl":     {Data: []byte(`{{define "layout"}}gone from doc{{end}}`)},
+		"doc/install.md":   {Data: []byte("Install.")},
+		"doc/tutorial.md":  {Data: []byte("Tutorial.")},
+		"doc/old.md":       {Data: []byte("---\nredirect: /doc/install\n---\n")},
+		"blog/broken.md":   {Data: []byte("{{undefined}}")},
+		"blog/500.tmpl":    {Data: []byte(`{{define "layout"}}{{call .missing}}{{end}}`)},
+		"blog/index.md":    {Data: []byte("Blog.")},
+		"blog/a/b/deep.md": {Data: []byte("Deep.")},
+	}
+	site := NewSite(fsys)
+
+	for _, tt := range []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/nope", 404, "404: not found"},
+		{"/doc/instal", 404, "404: not found; did you mean /doc/install"},
+		{"/DOC/Install.html", 404, "404: not found; did you mean /doc/install"},
+		{"/tutorial", 404, "404: not found; did you mean /doc/tutorial"},
+		{"/blog/xyzzy", 404, "404: not found"},
+		{"/doc/ol", 404, "404: not found"}, // no suggestion of redirect pages
+		{"/wp-admin/instal", 404, "404: not found"},
+		{"/wp-admin/tutorial", 404, "404: not found; did you mean /doc/tutorial"},
+	} {
+		rw := httptest.NewRecorder()
+		site.ServeHTTP(rw, httptest.NewRequest("GET", tt.path, nil))
+		if rw.Code != tt.status || rw.Body.String() != tt.body {
+			t.Errorf("GET %s: %d %q, want %d %q", tt.path, rw.Code, rw.Body.String(), tt.status, tt.body)
+		}
+	}
+
+	for _, tt := range []struct {
+		path string
+		body string
+	}{
+		{"/doc/x", "410: gone from doc"},
+		{"/doc/sub/x", "410: gone from doc"},
+		{"/x", "error: 410 gone"},
+	} {
+		rw := httptest.NewRecorder()
+		site.ServeErrorStatus(rw, httptest.NewRequest("GET", tt.path, nil), errors.New("gone"), http.StatusGone)
+		if rw.Code != 410 || rw.Body.String() != tt.body {
+			t.Errorf("ServeErrorStatus %s: %d %q, want 410 %q", tt.path, rw.Code, rw.Body.String(), tt.body)
+		}
+	}
+
+	// A broken error page falls back to a static page.
+	rw := httptest.NewRecorder()
+	site.ServeHTTP(rw, httptest.NewRequest("GET", "/blog/broken", nil))
+	if body := rw.Body.String(); rw.Code != 500 || !strings.Contains(body, "<h1>500 Internal Server Error</h1>") {
+		t.Errorf("GET /blog/broken: %d %q, want 500 fallback page", rw.Code, body)
+	}
+	if ct := rw.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("GET /blog/broken: Content-Type = %q", ct)
+	}
+}
+
+func TestPageJSON(t *testing.T) {
+	site := NewSite(fstest.MapFS{
+		"site.tmpl":     {Data: []byte(`<html>{{.Content}}</html>`)},
+		"doc/page.md":   {Data: []byte("---\ntitle: A Page\ntags: [x, y]\n---\n## Install *Go+*\n\nText.\n\n### Next\n")},
+		"doc/plain.md":  {Data: []byte("<!DOCTYPE html>\n<h2 id=\"top\">Top</h2>\n")},
+		"doc/broken.md": {Data: []byte("{{undefined}}")},
+	})
+	get := func(path string, hdr ...string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest("GET", path, nil)
+		for i := 0; i+1 < len(hdr); i += 2 {
+			r.Header.Set(hdr[i], hdr[i+1])
+		}
+		rw := httptest.NewRecorder()
+		site.ServeHTTP(rw, r)
+		return rw
+	}
+	decode := func(rw *httptest.ResponseRecorder) *pageJSON {
+		t.Helper()
+		if ct := rw.Header().Get("Content-Type"); rw.Code != 200 || ct != "application/json; charset=utf-8" {
+			t.Fatalf("%d %s:\n%s", rw.Code, ct, rw.Body)
+		}
+		js := new(pageJSON)
+		if err := json.Unmarshal(rw.Body.Bytes(), js); err != nil {
+			t.Fatal(err)
+		}
+		return js
+	}
+
+	js := decode(get("/doc/page?m=json"))
+	if js.URL != "/doc/page" || js.CanonicalURL != "http://example.com/doc/page" {
+		t.Errorf("URL = %q, CanonicalURL = %q", js.URL, js.CanonicalURL)
+	}
+	if js.Meta["title"] != "A Page" || fmt.Sprint(js.Meta["tags"]) != "[x y]" || js.Meta["FileData"] != nil {
+		t.Errorf("Meta = %v", js.Meta)
+	}
+	if !strings.Contains(js.Content, "<p>Text.</p>") || strings.Contains(js.Content, "<html>") {
+		t.Errorf("Content = %q", js.Content)
+	}
+	want := []tocEntry{{2, "install-go", "Install Go+"}, {3, "next", "Next"}}
+	if fmt.Sprint(js.TOC) != fmt.Sprint(want) {
+		t.Errorf("TOC = %v, want %v", js.TOC, want)
+	}
+
+	js = decode(get("/doc/plain", "Accept", "application/json", "X-Forwarded-Proto", "https"))
+	if js.CanonicalURL != "https://example.com/doc/plain" || len(js.TOC) != 1 || js.TOC[0].ID != "top" {
+		t.Errorf("standalone page: CanonicalURL = %q, TOC = %v", js.CanonicalURL, js.TOC)
+	}
+
+	rw := get("/doc/page", "Accept", "text/html, application/json")
+	if ct := rw.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") || !strings.Contains(rw.Body.String(), "<html>") {
+		t.Errorf("GET /doc/page (HTML first): %s %q", ct, rw.Body)
+	}
+	if vary := strings.Join(rw.Header().Values("Vary"), ", "); !strings.Contains(vary, "Accept") {
+		t.Errorf("Vary = %q", vary)
+	}
+
+	rw = get("/doc/page?m=json")
+	if rw2 := get("/doc/page?m=json", "If-None-Match", rw.Header().Get("ETag")); rw2.Code != 304 {
+		t.Errorf("conditional GET: %d, want 304", rw2.Code)
+	}
+
+	if rw := get("/doc/broken?m=json"); rw.Code != 500 {
+		t.Errorf("GET /doc/broken?m=json: %d, want 500", rw.Code)
+	}
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	site := NewSite(fstest.MapFS{
+		"site.tmpl":    {Data: []byte(`{{.Content}}`)},
+		"blog/a.md":    {Data: []byte("---\ntitle: A\ndate: 2021-01-02\nyear: 2021\n---\n")},
+		"blog/b.md":    {Data: []byte("---\ntitle: B\ndate: 2022-03-04\nyear: 2022\n---\n")},
+		"blog/c.md":    {Data: []byte("---\ntitle: C\nyear: 2021\n---\n")},
+		"date.html":    {Data: tmplPage(`{{date "Jan 2, 2006" "2021-05-01"}} {{date "2006" (page "/blog/a").date}} [{{date "2006" .missing}}]`)},
+		"default.html": {Data: tmplPage(`{{.missing | default "none"}} {{"x" | default "none"}}`)},
+		"dict.html":    {Data: tmplPage(`{{with dict "a" 1 "b" (list 2 3)}}{{.a}} {{index .b 1}}{{end}}`)},
+		"sort.html":    {Data: tmplPage(`{{range (pages "/blog/*" | sortBy "date" "desc")}}{{.title}}{{end}} {{range (pages "/blog/*" | sortBy "title")}}{{.title}}{{end}}`)},
+		"group.html":   {Data: tmplPage(`{{range (pages "/blog/*" | sortBy "year" | groupBy "year")}}{{.Key}}:{{range .Pages}}{{.title}}{{end}};{{end}}`)},
+		"json.html":    {Data: tmplPage(`<script>var x = {{jsonify (dict "x" "<y>" "n" 1)}};</script>`)},
+		"bad.html":     {Data: tmplPage(`{{dict "a"}}`)},
+		"badsort.html": {Data: tmplPage(`{{sortBy "x" "up" (pages "/blog/*")}}`)},
+	})
+	for _, tt := range []struct {
+		path string
+		body string
+	}{
+		{"/date", "May 1, 2021 2021 []"},
+		{"/default", "none x"},
+		{"/dict", "1 3"},
+		{"/sort", "BAC ABC"},
+		{"/group", "2021:AC;2022:B;"},
+		{"/json", `<script>var x = {"n":1,"x":"\u003cy\u003e"};</script>`},
+	} {
+		rw := httptest.NewRecorder()
+		site.ServeHTTP(rw, httptest.NewRequest("GET", tt.path, nil))
+		if body := strings.TrimSpace(rw.Body.String()); rw.Code != 200 || body != tt.body {
+			t.Errorf("GET %s: %d %q, want %q", tt.path, rw.Code, body, tt.body)
+		}
+	}
+	for _, path := range []string{"/bad", "/badsort"} {
+		rw := httptest.NewRecorder()
+		site.ServeHTTP(rw, httptest.NewRequest("GET", path, nil))
+		if rw.Code != 500 {
+			t.Errorf("GET %s: %d, want 500", path, rw.Code)
+		}
+	}
+}
+
+func TestRenderLimits(t *testing.T) {
+	ten := `{{$l := list 0 1 2 3 4 5 6 7 8 9}}`
+	loop := ten + strings.Repeat(`{{range $l}}`, 9) + "." + strings.Repeat(`{{end}}`, 9)
+	site := NewSite(fstest.MapFS{
+		"site.tmpl":  {Data: []byte(`{{.Content}}`)},
+		"small.html": {Data: tmplPage(`small`)},
+		"big.html":   {Data: tmplPage(ten + `{{range $l}}{{range $l}}{{range $l}}x{{end}}{{end}}{{end}}`)},
+		"slow.html":  {Data: tmplPage(loop)},
+		"wait.html":  {Data: tmplPage(`{{wait}}`)},
+		"spin.html":  {Data: tmplPage(ten + strings.Repeat(`{{range $l}}`, 9) + "{{$x := tick}}" + strings.Repeat(`{{end}}`, 9))},
+	})
+	var ticks int64
+	unblock := make(chan bool)
+	site.Funcs(template.FuncMap{
+		"wait": func() string { <-unblock; return "" },
+		"tick": func() int64 { return atomic.AddInt64(&ticks, 1) },
+	})
+	var trace Trace
+	get := func(path string) *httptest.ResponseRecorder {
+		trace = Trace{}
+		rw := httptest.NewRecorder()
+		site.ServeHTTP(rw, WithTrace(httptest.NewRequest("GET", path, nil), &trace))
+		return rw
+	}
+
+	site.SetRenderLimits(0, 500)
+	if rw := get("/small"); rw.Code != 200 {
+		t.Errorf("GET /small: %d, want 200", rw.Code)
+	}
+	if rw := get("/big"); rw.Code != 500 {
+		t.Errorf("GET /big with size limit: %d, want 500", rw.Code)
+	}
+
+	site.SetRenderLimits(50*time.Millisecond, 0)
+	if rw := get("/big"); rw.Code != 200 || len(strings.TrimSpace(rw.Body.String())) != 1000 {
+		t.Errorf("GET /big without size limit: %d, %d bytes", rw.Code, rw.Body.Len())
+	}
+	start := time.Now()
+	if rw := get("/slow"); rw.Code != 500 {
+		t.Errorf("GET /slow with time limit: %d, want 500", rw.Code)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("GET /slow took %v, want about 50ms", d)
+	}
+
+	// An execution stuck in a function call is abandoned at the time limit.
+	if rw := get("/wait"); rw.Code != 500 || trace.Abandoned != 1 {
+		t.Errorf("GET /wait with time limit: %d, trace.Abandoned = %d, want 500, 1", rw.Code, trace.Abandoned)
+	}
+	close(unblock)
+
+	// An abandoned execution that writes nothing
+	// still stops at its next template function call.
+	if rw := get("/spin"); rw.Code != 500 {
+		t.Errorf("GET /spin with time limit: %d, want 500", rw.Code)
+	}
+	n := atomic.LoadInt64(&ticks)
+	time.Sleep(100 * time.Millisecond)
+	if m := atomic.LoadInt64(&ticks); m > n+1 {
+		t.Errorf("abandoned /spin execution still running: %d more ticks", m-n)
+	}
+}
+
+// tmplPage returns an HTML page file whose body is the template text.
+func tmplPage(text string) []byte {
+	return []byte("<!--{\n\"template\": true\n}-->\n" + text)
+}
+
+func TestCollections(t *testing.T) {
+	fsys := fstest.MapFS{
+		"site.tmpl":              {Data: []byte(`{{.Content}}`)},
+		"data/users/a.yaml":      {Data: []byte("name: Ann\nsince: 2020-05-01\ntags: [edu, web]\n")},
+		"data/users/b.json":      {Data: []byte(`[{"name": "Bob", "since": "2021-01-10", "tags": ["cli"]}, {"name": "Cy"}]`)},
+		"data/users/c.csv":       {Data: []byte("name,stars,since\nDee,12,2019-03-01\nEve,3.5,2022-07-07\n")},
+		"data/users/notes.txt":   {Data: []byte("not a record")},
+		"data/users/sub/x.yaml":  {Data: []byte("name: Sub\n")},
+		"data/bad/x.yaml":        {Data: []byte("- 1\n- 2\n")},
+		"users.html":             {Data: tmplPage(`{{range collection "/data/users"}}{{.name}} {{end}}`)},
+		"tagged.html":            {Data: tmplPage(`{{range (collection "/data/users" | where "tags" "contains" "edu")}}{{.name}}{{end}}`)},
+		"stars.html":             {Data: tmplPage(`{{range (collection "/data/users" | where "stars" ">" 3 | sortBy "stars")}}{{.name}}{{end}}`)},
+		"recent.html":            {Data: tmplPage(`{{range (collection "/data/users" | where "since" ">=" "2021-01-01" | sortBy "since" "desc" | first 1)}}{{.name}}{{end}}`)},
+		"data/users/index.html":  {Data: tmplPage(`{{len (collection ".")}}`)},
+		"bad.html":               {Data: tmplPage(`{{collection "/data/bad"}}`)},
+		"missing.html":           {Data: tmplPage(`{{collection "/data/missing"}}`)},
+		"badwhere.html":          {Data: tmplPage(`{{where "x" "~" 1 (collection "/data/users")}}`)},
+		"data/users/sub/y.md":    {Data: []byte("ignored")},
+		"data/users/empty.yaml":  {Data: []byte("")},
+		"data/users/README.md":   {Data: []byte("About users.")},
+		"data/users/.hidden.txt": {Data: []byte("x")},
+	}
+	site := NewSite(fsys)
+	get := func(path string) (int, string) {
+		rw := httptest.NewRecorder()
+		site.ServeHTTP(rw, httptest.NewRequest("GET", path, nil))
+		return rw.Code, strings.TrimSpace(rw.Body.String())
+	}
+
+	for _, tt := range []struct {
+		path string
+		body string
+	}{
+		{"/users", "Ann Bob Cy Dee Eve"},
+		{"/tagged", "Ann"},
+		{"/stars", "EveDee"},
+		{"/recent", "Eve"},
+		{"/data/users/", "5"},
+	} {
+		if code, body := get(tt.path); code != 200 || body != tt.body {
+			t.Errorf("GET %s: %d %q, want %q", tt.path, code, body, tt.body)
+		}
+	}
+	for _, path := range []string{"/bad", "/missing", "/badwhere"} {
+		if code, _ := get(path); code != 500 {
+			t.Errorf("GET %s: %d, want 500", path, code)
+		}
+	}
+
+	recs, err := site.Collection("/data/users")
+	if err != nil || len(recs) != 5 || recs[3]["stars"] != 12 || recs[4]["stars"] != 3.5 {
+		t.Errorf("Collection: %v, %v", recs, err)
+	}
+
+	// Changing, adding, or removing a record file updates the collection
+	// and the pages listing it.
+	fsys["data/users/a.yaml"] = &fstest.MapFile{Data: []byte("name: Anna\n"), ModTime: time.Now()}
+	fsys["data/users/f.yaml"] = &fstest.MapFile{Data: []byte("name: Fay\n")}
+	if code, body := get("/users"); code != 200 || body != "Anna Bob Cy Dee Eve Fay" {
+		t.Errorf("GET /users after edit: %d %q", code, body)
+	}
+	delete(fsys, "data/users/b.json")
+	if code, body := get("/users"); code != 200 || body != "Anna Dee Eve Fay" {
+		t.Errorf("GET /users after remove: %d %q", code, body)
+	}
+}
+
+func TestPagination(t *testing.T) {
+	fsys := fstest.MapFS{
+		"site.tmpl":     {Data: []byte(`{{with .Paginator.Next}}next={{.}} {{end}}{{.Content}}`)},
+		"blog/index.md": {Data: []byte("{{with paginate \"date\" \"desc\" 2 (pages \"posts/*\")}}{{range .Pages}}{{.title}}{{end}} {{.Number}}/{{.NumPages}} prev={{.Prev}}{{range .Links}} {{.Number}}{{if .Current}}*{{end}}{{end}}{{end}}")},
+		"about.md":      {Data: []byte("About.")},
+		"error.tmpl":    {Data: []byte(`{{define "layout"}}{{.status}}{{end}}`)},
+	}
+	for i, title := range []string{"A", "B", "C", "D", "E"} {
+		fsys["blog/posts/"+strings.ToLower(title)+".md"] = &fstest.MapFile{
+			Data: []byte(fmt.Sprintf("---\ntitle: %s\ndate: 2021-01-%02d\n---\n", title, i+1)),
+		}
+	}
+	site := NewSite(fsys)
+	get := func(path string) *httptest.ResponseRecorder {
+		rw := httptest.NewRecorder()
+		site.ServeHTTP(rw, httptest.NewRequest("GET", path, nil))
+		return rw
+	}
+
+	for _, tt := range []struct {
+		path string
+		body string
+	}{
+		{"/blog/", "next=/blog/page/2/ <p>ED 1/3 prev= 1* 2 3</p>"},
+		{"/blog/page/2/", "next=/blog/page/3/ <p>CB 2/3 prev=/blog/ 1 2* 3</p>"},
+		{"/blog/page/3/", "<p>A 3/3 prev=/blog/page/2/ 1 2 3*</p>"},
+		{"/blog/page/2/", "next=/blog/page/3/ <p>CB 2/3 prev=/blog/ 1 2* 3</p>"}, // from output cache
+	} {
+		rw := get(tt.path)
+		if body := strings.TrimSpace(rw.Body.String()); rw.Code != 200 || body != tt.body {
+			t.Errorf("GET %s: %d %q, want %q", tt.path, rw.Code, body, tt.body)
+		}
+	}
+
+	for _, tt := range []struct {
+		path     string
+		code     int
+		location string
+	}{
+		{"/blog/page/1/", 301, "/blog/"},
+		{"/blog/page/2", 301, "/blog/page/2/"},
+		{"/blog/page/4/", 404, ""},
+		{"/blog/page/4611686018427387905/", 404, ""},
+		{"/blog/page/02/", 404, ""},
+		{"/blog/page/0/", 404, ""},
+		{"/about/page/2/", 404, ""},
+		{"/nope/page/2/", 404, ""},
+	} {
+		rw := get(tt.path)
+		if loc := rw.Header().Get("Location"); rw.Code != tt.code || loc != tt.location {
+			t.Errorf("GET %s: %d %q, want %d %q", tt.path, rw.Code, loc, tt.code, tt.location)
+		}
+	}
+
+	rw := get("/blog/page/3/?m=json")
+	var js struct{ Paginator *Paginator }
+	if err := json.Unmarshal(rw.Body.Bytes(), &js); err != nil || js.Paginator == nil {
+		t.Fatalf("GET /blog/page/3/?m=json: %v\n%s", err, rw.Body)
+	}
+	if pg := js.Paginator; pg.Number != 3 || pg.Total != 5 || len(pg.Pages) != 1 || pg.Pages[0]["title"] != "A" || pg.Next != "" {
+		t.Errorf("GET /blog/page/3/?m=json: Paginator = %+v", pg)
+	}
+}
@@ -19,7 +19,41 @@ import (
 // A siteDir is a site extended with a known directory for interpreting relative paths.
 type siteDir struct {
 	*Site
-	dir string
+	dir  string
+	lang string       // language of the page being rendered, or ""
+	deps *depRecorder // files read during rendering, or nil
+}
+
+// readFile is like Site.readFile but records the file in site.deps.
+func (site *siteDir) readFile(dir, file string) ([]byte, error) {
+	if strings.HasPrefix(file, "/") {
+		file = path.Clean(file)
+	} else {
+		file = path.Join(dir, file)
+	}
+	file = strings.Trim(file, "/")
+	if file == "" {
+		file = "."
+	}
+	if _, err := site.deps.stat(site.fs, file); err != nil {
+		return nil, err
+	}
+	return fs.ReadFile(site.fs, file)
+}
+
+// openPage is like Site.openPage but records in site.deps
+// each of the files that could hold the page.
+func (site *siteDir) openPage(file string) (*pageFile, error) {
+	if site.deps != nil {
+		for _, f := range site.pageFiles(pageKey(file)) {
+			site.deps.stat(site.fs, f)
+		}
+	}
+	pf, err := site.Site.openPage(file)
+	if err == nil {
+		site.deps.loaded(pf)
+	}
+	return pf, err
 }
 
 func toString(x interface{}) string {
@@ -98,7 +132,7 @@ func (site *siteDir) page(u string) (Page, error) {
 
 // Pages returns the pages found in files matching glob.
 func (site *Site) Pages(glob string) ([]Page, error) {
-	return (&siteDir{site, "."}).pages(glob)
+	return (&siteDir{site, ".", "", nil}).pages(glob)
 }
 
 // pages returns the page params for pages with urls matching glob.
@@ -115,17 +149,28 @@ func (site *siteDir) pages(glob string) ([]Page, error) {
 	if glob == "" {
 		glob = "."
 	}
-	matches, err := fs.Glob(site.fs, glob)
+	matches, err := site.deps.glob(site.fs, glob)
 	if err != nil {
 		return nil, err
 	}
 	var out []Page
 	for _, file := range matches {
+		lang, plain := site.fileLang(file)
+		if plain != file {
+			continue // translation like x.zh.md, listed in place of x.md below
+		}
+		if lang == site.defaultLang() && site.lang != lang {
+			// Prefer the translation into the current page's language, if any.
+			if p, err := site.openPage(path.Join(site.lang, pageKey(file))); err == nil {
+				out = append(out, p.page)
+				continue
+			}
+		}
 		if !strings.HasSuffix(file, ".md") && !strings.HasSuffix(file, ".html") {
 			f := path.Join(file, "index.md")
-			if _, err := fs.Stat(site.fs, f); err != nil {
+			if _, err := site.deps.stat(site.fs, f); err != nil {
 				f = path.Join(file, "index.html")
-				if _, err = fs.Stat(site.fs, f); err != nil {
+				if _, err = site.deps.stat(site.fs, f); err != nil {
 					continue
 				}
 			}
@@ -138,8 +183,14 @@ func (site *siteDir) pages(glob string) ([]Page, error) {
 		out = append(out, p.page)
 	}
 
+	// Sort translations by their default-language URLs,
+	// so that they keep the default-language pages' order.
+	key := func(p Page) string {
+		_, rest := site.splitLang(strings.TrimPrefix(p["URL"].(string), "/"))
+		return rest
+	}
 	sort.Slice(out, func(i, j int) bool {
-		return out[i]["URL"].(string) < out[j]["URL"].(string)
+		return key(out[i]) < key(out[j])
 	})
 	return out, nil
 }
@@ -0,0 +1,108 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package web
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Route classes recorded in a Trace, describing how the Site served a request.
+const (
+	ClassPage  = "page"  // a rendered page, from a file or ServePage
+	ClassDir   = "dir"   // a directory listing
+	ClassText  = "text"  // a text file rendered as HTML, or served as plain text
+	ClassFile  = "file"  // a raw file, served by http.FileServer
+	ClassError = "error" // an error page
+)
+
+// A Trace records how a Site served a single request.
+// To collect a Trace, attach it to the request using WithTrace
+// before passing the request to the Site.
+type Trace struct {
+	Class     string        // route class (ClassPage, ClassDir, and so on); "" if not served by a Site
+	Parse     time.Duration // time spent parsing templates
+	Execute   time.Duration // time spent executing templates
+	Markdown  time.Duration // time spent converting Markdown to HTML
+	Abandoned int           // template executions abandoned at the time limit, possibly still running
+}
+
+type traceKey struct{}
+
+// WithTrace returns a shallow copy of r with its context
+// changed to record into t how a Site serves the request.
+func WithTrace(r *http.Request, t *Trace) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), traceKey{}, t))
+}
+
+// traceOf returns the Trace attached to r, or nil if there is none.
+// Methods on a nil *Trace do nothing, so callers need not check.
+func traceOf(r *http.Request) *Trace {
+	if r == nil {
+		return nil
+	}
+	t, _ := r.Context().Value(traceKey{}).(*Trace)
+	return t
+}
+
+// setClass records the route class c.
+// Errors always win: once a request is classified as an error,
+// it stays one.
+func (t *Trace) setClass(c string) {
+	if t != nil && t.Class != ClassError {
+		t.Class = c
+	}
+}
+
+// abandon records a template execution abandoned at the time limit.
+func (t *Trace) abandon() {
+	if t != nil {
+		t.Abandoned++
+	}
+}
+
+// Rendering phases timed by a Trace.
+const (
+	phaseParse = iota
+	phaseExecute
+	phaseMarkdown
+)
+
+// since adds the time elapsed since start to the given phase.
+func (t *Trace) since(phase int, start time.Time) {
+	if t == nil {
+		return
+	}
+	d := time.Since(start)
+	switch phase {
+	case phaseParse:
+		t.Parse += d
+	case phaseExecute:
+		t.Execute += d
+	case phaseMarkdown:
+		t.Markdown += d
+	}
+}
+
+// serverTiming returns the trace in the form of a Server-Timing header value.
+func (t *Trace) serverTiming() string {
+	var list []string
+	for _, m := range []struct {
+		name string
+		d    time.Duration
+	}{
+		{"parse", t.Parse},
+		{"execute", t.Execute},
+		{"markdown", t.Markdown},
+	} {
+		if m.d > 0 {
+			list = append(list, fmt.Sprintf("%s;dur=%.3f", m.name, float64(m.d)/float64(time.Millisecond)))
+		}
+	}
+	return strings.Join(list, ", ")
+}
@@ -0,0 +1,134 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A jsonPath is a parsed path into a JSON value, as used by “json” checks.
+// Each element is either a string (an object key) or an int (an array index).
+type jsonPath []interface{}
+
+// parseJSONPath parses a path like .Files[0].Name.
+// The path "." denotes the entire value.
+// An object key that is not an identifier can be written as ["key"].
+func parseJSONPath(s string) (jsonPath, error) {
+	if s == "." {
+		return jsonPath{}, nil
+	}
+	var path jsonPath
+	rest := s
+	for rest != "" {
+		switch {
+		case rest[0] == '.':
+			i := 1
+			for i < len(rest) && rest[i] != '.' && rest[i] != '[' {
+				i++
+			}
+			if i == 1 {
+				return nil, fmt.Errorf("invalid JSON path %q: missing key after .", s)
+			}
+			path = append(path, rest[1:i])
+			rest = rest[i:]
+		case rest[0] == '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSON path %q: missing ]", s)
+			}
+			elem := rest[1:end]
+			if strings.HasPrefix(elem, `"`) {
+				key, err := strconv.Unquote(elem)
+				if err != nil {
+					return nil, fmt.Errorf("invalid JSON path %q: bad key %s", s, elem)
+				}
+				path = append(path, key)
+			} else {
+				n, err := strconv.Atoi(elem)
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("invalid JSON path %q: bad index %s", s, elem)
+				}
+				path = append(path, n)
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("invalid JSON path %q: must begin with . or [", s)
+		}
+	}
+	return path, nil
+}
+
+// value returns the value at the path in the JSON text body,
+// formatted for comparison: strings are returned as is,
+// and other values are returned as compact JSON.
+func (path jsonPath) value(body string) (string, error) {
+	d := json.NewDecoder(strings.NewReader(body))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return "", fmt.Errorf("invalid JSON: %v", err)
+	}
+	where := ""
+	for _, elem := range path {
+		switch elem := elem.(type) {
+		case string:
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return "", fmt.Errorf("%s is %s, not object", orDot(where), jsonKind(v))
+			}
+			where += "." + elem
+			if v, ok = m[elem]; !ok {
+				return "", fmt.Errorf("%s not found", where)
+			}
+		case int:
+			list, ok := v.([]interface{})
+			if !ok {
+				return "", fmt.Errorf("%s is %s, not array", orDot(where), jsonKind(v))
+			}
+			where += fmt.Sprintf("[%d]", elem)
+			if elem >= len(list) {
+				return "", fmt.Errorf("%s out of range (len %d)", where, len(list))
+			}
+			v = list[elem]
+		}
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	js, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(js), nil
+}
+
+func orDot(s string) string {
+	if s == "" {
+		return "."
+	}
+	return s
+}
+
+// jsonKind returns a description of the kind of the JSON value v.
+func jsonKind(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,288 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+)
+
+// A Runner runs test scripts, collecting the result of each case.
+type Runner struct {
+	// Parallel is the maximum number of scripts to run at once.
+	// The cases within a script always run in order, one at a time.
+	// If Parallel is zero, the scripts run one at a time.
+	Parallel int
+
+	// Update causes Runner.TestHandler to write snapshot files
+	// instead of checking them. The other methods ignore it,
+	// so that a server checking itself never writes files.
+	Update bool
+}
+
+// A Result is the result of running a single test case.
+// A script that cannot be read or parsed is reported as
+// a single Result with an empty Method and URL.
+type Result struct {
+	File   string        // script file
+	Line   int           // line number of the case in the file
+	Method string        // request method
+	URL    string        // request URL, as written in the script
+	Time   time.Duration // time taken to run the case
+	Err    error         // error describing failed checks; nil if the case passed
+}
+
+// Results is a list of results, in the order of the scripts and cases that produced them.
+type Results []*Result
+
+// RunHandler runs the test script files in fsys matched by glob
+// against the handler h.
+// The returned error reports a problem finding the scripts,
+// not a failing case; for those, see Results.Err.
+func (r *Runner) RunHandler(fsys fs.FS, glob string, h http.Handler) (Results, error) {
+	return r.run(fsys, glob, func(c *case_) error { return c.runHandler(h) })
+}
+
+// RunServer is like RunHandler but runs the scripts against the server at addr,
+// which is either a base URL like "https://goplus.org/"
+// or the address of an HTTP proxy like "localhost:8080".
+func (r *Runner) RunServer(fsys fs.FS, glob string, addr string) (Results, error) {
+	return r.run(fsys, glob, func(c *case_) error { return c.runServer(addr) })
+}
+
+// run runs the scripts matched by glob, calling do to run each case.
+//
+// Different scripts run concurrently, but the cases of a script run
+// in order, one at a time, since a later case may depend on an earlier one,
+// whether through a captured variable or a change to the server's state.
+func (r *Runner) run(fsys fs.FS, glob string, do func(*case_) error) (Results, error) {
+	files, err := fs.Glob(fsys, glob)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files match %#q", glob)
+	}
+
+	limit := r.Parallel
+	if limit < 1 {
+		limit = 1
+	}
+	sem := make(chan bool, limit)
+
+	var results Results
+	var wg sync.WaitGroup
+	for _, file := range files {
+		script, err := readScript(fsys, file)
+		if err != nil {
+			results = append(results, &Result{File: file, Err: err})
+			continue
+		}
+		var list []*Result
+		for _, c := range script.cases {
+			list = append(list, &Result{File: file, Line: c.line, Method: c.method, URL: c.url})
+		}
+		results = append(results, list...)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- true
+			defer func() { <-sem }()
+			for i, c := range script.cases {
+				start := time.Now()
+				list[i].Err = do(c)
+				list[i].Time = time.Since(start)
+			}
+		}()
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// readScript reads and parses the script file in fsys.
+func readScript(fsys fs.FS, file string) (*script, error) {
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, err
+	}
+	script, err := parseScript(file, string(data))
+	if err != nil {
+		return nil, err
+	}
+	dir, err := fs.Sub(fsys, path.Dir(file))
+	if err != nil {
+		return nil, err
+	}
+	script.setFiles(dir, "")
+	return script, nil
+}
+
+// Failed returns the number of results that are failures.
+func (rs Results) Failed() int {
+	n := 0
+	for _, r := range rs {
+		if r.Err != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Err returns an error listing the failures in rs,
+// grouped by script file, or nil if there are none.
+func (rs Results) Err() error {
+	var buf bytes.Buffer
+	hdr := ""
+	for _, r := range rs {
+		if r.Err == nil {
+			continue
+		}
+		if hdr != r.File {
+			fmt.Fprintf(&buf, "# %s\n", r.File)
+			hdr = r.File
+		}
+		if r.Method != "" {
+			fmt.Fprintf(&buf, "## %s %s\n", r.Method, r.URL)
+		}
+		fmt.Fprintf(&buf, "%v\n", r.Err)
+	}
+	if buf.Len() > 0 {
+		return errors.New(buf.String())
+	}
+	return nil
+}
+
+// WriteJSON writes rs to w as JSON: an object with the
+// number of cases run and failed, and the list of results.
+// Each result's time is given in seconds, and its error,
+// if any, as a string.
+func (rs Results) WriteJSON(w io.Writer) error {
+	type jsonResult struct {
+		File   string  `json:"file"`
+		Line   int     `json:"line,omitempty"`
+		Method string  `json:"method,omitempty"`
+		URL    string  `json:"url,omitempty"`
+		Time   float64 `json:"time"`
+		Pass   bool    `json:"pass"`
+		Error  string  `json:"error,omitempty"`
+	}
+	out := struct {
+		Total   int          `json:"total"`
+		Failed  int          `json:"failed"`
+		Results []jsonResult `json:"results"`
+	}{
+		Total:   len(rs),
+		Failed:  rs.Failed(),
+		Results: []jsonResult{},
+	}
+	for _, r := range rs {
+		jr := jsonResult{
+			File:   r.File,
+			Line:   r.Line,
+			Method: r.Method,
+			URL:    r.URL,
+			Time:   r.Time.Seconds(),
+			Pass:   r.Err == nil,
+		}
+		if r.Err != nil {
+			jr.Error = r.Err.Error()
+		}
+		out.Results = append(out.Results, jr)
+	}
+	data, err := json.MarshalIndent(out, "", "\t")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// JUnit XML report format, as understood by most CI systems and dashboards.
+type (
+	junitSuites struct {
+		XMLName  xml.Name     `xml:"testsuites"`
+		Tests    int          `xml:"tests,attr"`
+		Failures int          `xml:"failures,attr"`
+		Time     string       `xml:"time,attr"`
+		Suites   []junitSuite `xml:"testsuite"`
+	}
+	junitSuite struct {
+		Name     string      `xml:"name,attr"`
+		Tests    int         `xml:"tests,attr"`
+		Failures int         `xml:"failures,attr"`
+		Time     string      `xml:"time,attr"`
+		Cases    []junitCase `xml:"testcase"`
+	}
+	junitCase struct {
+		Name      string        `xml:"name,attr"`
+		Classname string        `xml:"classname,attr"`
+		Time      string        `xml:"time,attr"`
+		Failure   *junitFailure `xml:"failure,omitempty"`
+	}
+	junitFailure struct {
+		Message string `xml:"message,attr"`
+		Text    string `xml:",chardata"`
+	}
+)
+
+// WriteJUnit writes rs to w as a JUnit XML report,
+// with one test suite for each script file.
+func (rs Results) WriteJUnit(w io.Writer) error {
+	seconds := func(d time.Duration) string { return fmt.Sprintf("%.3f", d.Seconds()) }
+	var out junitSuites
+	var total time.Duration
+	var suite *junitSuite
+	var suiteTime time.Duration
+	for _, r := range rs {
+		if suite == nil || suite.Name != r.File {
+			if suite != nil {
+				suite.Time = seconds(suiteTime)
+			}
+			out.Suites = append(out.Suites, junitSuite{Name: r.File})
+			suite = &out.Suites[len(out.Suites)-1]
+			suiteTime = 0
+		}
+		name := r.Method + " " + r.URL
+		if r.Method == "" {
+			name = "parse"
+		}
+		jc := junitCase{Name: name, Classname: r.File, Time: seconds(r.Time)}
+		if r.Err != nil {
+			msg := "failed"
+			if r.Method == "" {
+				msg = "invalid script"
+			}
+			jc.Failure = &junitFailure{Message: msg, Text: r.Err.Error()}
+			suite.Failures++
+			out.Failures++
+		}
+		suite.Cases = append(suite.Cases, jc)
+		suite.Tests++
+		out.Tests++
+		suiteTime += r.Time
+		total += r.Time
+	}
+	if suite != nil {
+		suite.Time = seconds(suiteTime)
+	}
+	out.Time = seconds(total)
+
+	data, err := xml.MarshalIndent(out, "", "\t")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, data)
+	return err
+}
@@ -0,0 +1,328 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webtest
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// A selector is a parsed CSS selector, as used by “html” checks.
+// It supports a common subset of CSS: type, universal, class, ID,
+// and attribute selectors (with the operators = ~= |= ^= $= and *=),
+// combined with the descendant and child (>) combinators,
+// in comma-separated lists.
+type selector []complexSel
+
+// A complexSel is a sequence of compound selectors joined by combinators.
+type complexSel struct {
+	parts []compoundSel
+	combs []byte // combs[i] joins parts[i] and parts[i+1]: ' ' or '>'
+}
+
+// A compoundSel is a sequence of simple selectors, all of which must match.
+type compoundSel struct {
+	tag     string // element name, or "" for any
+	id      string
+	classes []string
+	attrs   []attrSel
+}
+
+// An attrSel is an attribute selector like [key op "val"].
+type attrSel struct {
+	key string
+	op  string // "" (attribute present), "=", "~=", "|=", "^=", "$=", or "*="
+	val string
+}
+
+// parseSelector parses the CSS selector s.
+func parseSelector(s string) (selector, error) {
+	p := &selParser{s: s}
+	var sel selector
+	for {
+		c, err := p.complex()
+		if err != nil {
+			return nil, err
+		}
+		sel = append(sel, c)
+		if p.i == len(p.s) {
+			return sel, nil
+		}
+		p.i++ // skip comma
+	}
+}
+
+// A selParser holds the state of parsing a selector.
+type selParser struct {
+	s string
+	i int
+}
+
+func (p *selParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid selector %q: %s", p.s, fmt.Sprintf(format, args...))
+}
+
+// space skips spaces and reports whether there were any.
+func (p *selParser) space() bool {
+	start := p.i
+	for p.i < len(p.s) && (p.s[p.i] == ' ' || p.s[p.i] == '\t') {
+		p.i++
+	}
+	return p.i > start
+}
+
+// ident parses and returns an identifier, or "" if there is none.
+func (p *selParser) ident() string {
+	start := p.i
+	for p.i < len(p.s) {
+		c := p.s[p.i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c >= 0x80 {
+			p.i++
+			continue
+		}
+		break
+	}
+	return p.s[start:p.i]
+}
+
+// complex parses a complex selector, stopping at a comma or the end of the input.
+func (p *selParser) complex() (complexSel, error) {
+	var c complexSel
+	p.space()
+	for {
+		cp, err := p.compound()
+		if err != nil {
+			return c, err
+		}
+		c.parts = append(c.parts, cp)
+		sawSpace := p.space()
+		if p.i == len(p.s) || p.s[p.i] == ',' {
+			return c, nil
+		}
+		comb := byte(' ')
+		if p.s[p.i] == '>' {
+			comb = '>'
+			p.i++
+			p.space()
+		} else if !sawSpace {
+			return c, p.errorf("unexpected %q", p.s[p.i:])
+		}
+		c.combs = append(c.combs, comb)
+	}
+}
+
+// compound parses a compound selector.
+func (p *selParser) compound() (compoundSel, error) {
+	var cp compoundSel
+	start := p.i
+	if p.i < len(p.s) && p.s[p.i] == '*' {
+		p.i++
+	} else {
+		cp.tag = strings.ToLower(p.ident())
+	}
+	for p.i < len(p.s) {
+		switch p.s[p.i] {
+		case '#':
+			p.i++
+			if cp.id = p.ident(); cp.id == "" {
+				return cp, p.errorf("missing ID after #")
+			}
+			continue
+		case '.':
+			p.i++
+			class := p.ident()
+			if class == "" {
+				return cp, p.errorf("missing class name after .")
+			}
+			cp.classes = append(cp.classes, class)
+			continue
+		case '[':
+			p.i++
+			a, err := p.attr()
+			if err != nil {
+				return cp, err
+			}
+			cp.attrs = append(cp.attrs, a)
+			continue
+		}
+		break
+	}
+	if p.i == start {
+		if p.i == len(p.s) {
+			return cp, p.errorf("missing selector")
+		}
+		return cp, p.errorf("unexpected %q", p.s[p.i:])
+	}
+	return cp, nil
+}
+
+// attr parses an attribute selector, after its opening [.
+func (p *selParser) attr() (attrSel, error) {
+	var a attrSel
+	p.space()
+	if a.key = strings.ToLower(p.ident()); a.key == "" {
+		return a, p.errorf("missing attribute name")
+	}
+	p.space()
+	for _, op := range []string{"=", "~=", "|=", "^=", "$=", "*="} {
+		if strings.HasPrefix(p.s[p.i:], op) {
+			a.op = op
+			p.i += len(op)
+			break
+		}
+	}
+	if a.op != "" {
+		p.space()
+		if p.i < len(p.s) && (p.s[p.i] == '"' || p.s[p.i] == '\'') {
+			q := p.s[p.i]
+			end := strings.IndexByte(p.s[p.i+1:], q)
+			if end < 0 {
+				return a, p.errorf("unterminated string")
+			}
+			a.val = p.s[p.i+1 : p.i+1+end]
+			p.i += 1 + end + 1
+		} else if a.val = p.ident(); a.val == "" {
+			return a, p.errorf("missing attribute value")
+		}
+		p.space()
+	}
+	if p.i == len(p.s) || p.s[p.i] != ']' {
+		return a, p.errorf("missing ]")
+	}
+	p.i++
+	return a, nil
+}
+
+// match reports whether the node n matches the selector.
+func (sel selector) match(n *html.Node) bool {
+	for _, c := range sel {
+		if c.matchAt(len(c.parts)-1, n) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAt reports whether n matches c.parts[i],
+// with its ancestors matching c.parts[:i] as required by the combinators.
+func (c complexSel) matchAt(i int, n *html.Node) bool {
+	if !c.parts[i].match(n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	for p := n.Parent; p != nil && p.Type == html.ElementNode; p = p.Parent {
+		if c.matchAt(i-1, p) {
+			return true
+		}
+		if c.combs[i-1] == '>' {
+			break
+		}
+	}
+	return false
+}
+
+// match reports whether the node n matches the compound selector.
+func (cp compoundSel) match(n *html.Node) bool {
+	if n.Type != html.ElementNode || cp.tag != "" && n.Data != cp.tag {
+		return false
+	}
+	if cp.id != "" && attr(n, "id") != cp.id {
+		return false
+	}
+	for _, class := range cp.classes {
+		if !hasWord(attr(n, "class"), class) {
+			return false
+		}
+	}
+	for _, a := range cp.attrs {
+		v, ok := "", false
+		for _, x := range n.Attr {
+			if x.Key == a.key {
+				v, ok = x.Val, true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+		switch a.op {
+		case "=":
+			ok = v == a.val
+		case "~=":
+			ok = hasWord(v, a.val)
+		case "|=":
+			ok = v == a.val || strings.HasPrefix(v, a.val+"-")
+		case "^=":
+			ok = a.val != "" && strings.HasPrefix(v, a.val)
+		case "$=":
+			ok = a.val != "" && strings.HasSuffix(v, a.val)
+		case "*=":
+			ok = a.val != "" && strings.Contains(v, a.val)
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// hasWord reports whether the space-separated list contains word.
+func hasWord(list, word string) bool {
+	for _, f := range strings.Fields(list) {
+		if f == word {
+			return true
+		}
+	}
+	return false
+}
+
+// attr returns the value of n's attribute with the given key, or "".
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// selectAll returns the nodes in the tree rooted at n matching sel,
+// in document order.
+func selectAll(n *html.Node, sel selector) []*html.Node {
+	var list []*html.Node
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if sel.match(n) {
+			list = append(list, n)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return list
+}
+
+// nodeText returns the text content of n, like the DOM's textContent,
+// with runs of white space collapsed to single spaces
+// and leading and trailing space removed.
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
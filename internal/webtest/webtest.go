@@ -21,6 +21,12 @@
 // They run the entire script and return a multiline error summarizing
 // any problems.
 //
+// A Runner runs scripts with more control: it runs scripts concurrently,
+// up to a limit, and returns the result of each case, which can be written
+// as JSON or as a JUnit XML report for use by dashboards and CI systems.
+// HandlerWithCheck uses a Runner to serve the results of checking a handler,
+// making it suitable as a smoke test of a deployed server.
+//
 // Scripts
 //
 // A script is a text file containing a sequence of cases, separated by blank lines.
@@ -38,7 +44,8 @@
 //
 // Requests
 //
-// Each case begins with a line starting with GET, HEAD, or POST.
+// Each case begins with a line starting with a request method:
+// GET, HEAD, POST, PUT, PATCH, DELETE, or OPTIONS.
 // The argument (the remainder of the line) is the URL to be used in the request.
 // Following this line, the request can be further customized using
 // lines of the form
@@ -53,7 +60,8 @@
 // The verb “hint” specifies text to be printed if the test case fails, as a
 // hint about what might be wrong.
 //
-// The verbs “postbody”, “postquery”, and “posttype” customize a POST request.
+// The verbs “postbody”, “postquery”, and “posttype” customize a POST request,
+// or the body of any other request except GET and HEAD.
 //
 // For example:
 //
@@ -79,6 +87,40 @@
 // This stanza sends a request with post body “x=hello+world&y=Go+%26+You”.
 // (The multiline syntax is described in detail below.)
 //
+// The verb “body”, followed by @ and a file name, sends the content of the file
+// as the request body. The “multipart” verb sends a multipart form
+// (Content-Type “multipart/form-data”), given as a sequence of key-value
+// pairs like “postquery”, except that a value of @ and a file name
+// uploads that file. In both, file names are relative to the directory
+// containing the script, and the default posted Content-Type of a file
+// is determined by its extension. For example:
+//
+//	PUT /admin/config
+//	body @testdata/config.json
+//
+//	POST /upload
+//	multipart
+//		version=go1.17
+//		file=@testdata/go1.17.src.tar.gz
+//
+// A case can specify only one of “postbody”, “postquery”, “body”, and “multipart”.
+//
+// The verb “header” adds a header line to the request, written as in HTTP,
+// with a colon after the key. The verb “cookie” adds a cookie, written as
+// name=value. For example:
+//
+//	GET /api/user
+//	header Accept-Language: fr
+//	cookie session=abc123
+//
+// Cookies set by responses are also saved and sent with later requests
+// in the same script, as a browser would.
+//
+// The verb “follow”, which takes no text, directs the case to follow
+// redirects, up to a limit of 10, and apply its checks to the final response.
+// As in browsers, a redirected POST becomes a GET, except after a
+// 307 or 308 redirect.
+//
 // Checks
 //
 // By default, a stanza like the ones above checks only that the request
@@ -94,9 +136,13 @@
 // The possible values for <value> are:
 //
 //	body - the full response body
+//	chain - the URLs requested by a case using “follow”, one per line
 //	code - the HTTP status code
 //	header <key> - the value in the header line with the given key
+//	html <selector> - the text of the body's HTML elements matching the CSS selector
+//	json <path> - the value at the given path in the body's JSON
 //	redirect - the target of a redirect, as found in the Location header
+//	time - the time taken to make the request and read the response
 //	trimbody - the response body, trimmed
 //
 // If a case contains no check of “code”, then it defaults to checking that
@@ -108,6 +154,24 @@
 // reduced to single spaces, leading and trailing spaces removed on
 // each line, and blank lines removed.
 //
+// The “json” value is found by decoding the body as JSON and then following
+// the path, a sequence of object keys and array indexes like “.Files[0].Name”;
+// a key that is not a simple name can be written in brackets, as in “["go-version"]”,
+// and the path “.” denotes the entire body.
+// If the value found is a string, it is used as is; otherwise it is formatted
+// as compact JSON, so that for example a number is “42” and a list is “[1,2]”.
+//
+// The “html” value is found by parsing the body as HTML and selecting the
+// elements matching the selector, which continues up to the operator and
+// so may contain spaces. Selectors can use element names, classes, IDs,
+// attributes ([name], [name=value], [name^=prefix], and so on),
+// and the descendant and child (>) combinators, in comma-separated lists.
+// The value is the text content of each matching element,
+// with runs of white space reduced to single spaces,
+// one element per line. Since neither “html” nor “json” values end
+// in a newline, the final newline of a multiline text is ignored
+// when checking them.
+//
 // The possible operators for <op> are:
 //
 //	== - the value must be equal to the text
@@ -116,6 +180,24 @@
 //	!~ - the value must not match the text interprted as a regular expression
 //	contains  - the value must contain the text as a substring
 //	!contains - the value must not contain the text as a substring
+//	count - the number of elements matched by an html selector must be the text
+//	snapshot - the value must be equal to the content of the file named by the text
+//	<  - the time must be less than the text, a duration like “200ms”
+//	>  - the time must be greater than the text
+//
+// The operators < and > can only be used with “time”, and “time” can only
+// be used with them.
+//
+// A snapshot file name is interpreted relative to the directory containing the script.
+// When scripts are run with the TestHandler method of a Runner whose
+// Update field is set, snapshot checks write the value to the file instead,
+// creating or updating it. A test usually sets Update from a flag of its own:
+//
+//	var update = flag.Bool("update", false, "update snapshot files")
+//
+//	func TestSite(t *testing.T) {
+//		(&webtest.Runner{Update: *update}).TestHandler(t, "testdata/*.txt", handler)
+//	}
 //
 // For example:
 //
@@ -130,6 +212,52 @@
 //	body ~ Got1xxResponse.*// Go 1\.11
 //	body ~ GotFirstResponseByte func\(\)\s*$
 //
+//	GET /dl/?mode=json
+//	json .[0].stable == true
+//	json .[0].files[0].filename ~ ^go.*\.src\.tar\.gz$
+//
+//	GET /doc/
+//	html h1 == Documentation
+//	html .Article ul > li count 4
+//
+//	GET /robots.txt
+//	body snapshot testdata/robots.golden
+//	time < 200ms
+//
+//	GET /doc/install
+//	follow
+//	chain ==
+//		/doc/install
+//		/doc/install/
+//
+// Variables
+//
+// A case can capture a value from its response into a variable,
+// for use by later cases in the same script, using a line of the form
+//
+//	capture <name> <value> [<key>] [~ <regexp>]
+//
+// where <value> and <key> are as in checks. If a regular expression is given,
+// the value must match it, and the variable is set to the text matched by
+// the last parenthesized subexpression, or else the entire match.
+// Captures are made before the case's checks are applied.
+//
+// A later case refers to the variable as ${name} in its URL, request headers,
+// cookies, post body, or check texts. Using a variable that has not been
+// captured is an error. For example:
+//
+//	POST /share
+//	postbody package main
+//	capture id body ~ ^([a-z0-9]+)$
+//
+//	GET /p/${id}
+//	body contains package main
+//
+// Since the cases of a script may depend on each other, through captured
+// variables or otherwise, a Runner always runs the cases of a script in order,
+// one at a time. Different scripts may run concurrently, so they should not
+// depend on each other.
+//
 // Multiline Texts
 //
 // The <text> in a request or check line can take a multiline form,
@@ -150,93 +278,122 @@ package webtest
 
 import (
 	"bytes"
-	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"io/ioutil"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"net/http/cookiejar"
 	"net/http/httptest"
+	"net/textproto"
 	"net/url"
+	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 	"unicode/utf8"
+
+	"golang.org/x/net/html"
 )
 
 // HandlerWithCheck returns an http.Handler that responds to each request
 // by running the test script files mached by glob against the handler h.
 // If the tests pass, the returned http.Handler responds with status code 200.
-// If they fail, it prints the details and responds with status code 503
-// (service unavailable).
+// If they fail, it prints the details and responds with status code 500
+// (internal server error).
+//
+// The scripts run concurrently, at most four at a time; see Runner.HandlerWithCheck.
 func HandlerWithCheck(h http.Handler, path string, fsys fs.FS, glob string) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path == path {
-			err := CheckHandler(fsys, glob, h)
-			if err != nil {
-				http.Error(w, "webtest.CheckHandler failed:\n"+err.Error()+"\n", http.StatusInternalServerError)
-			} else {
-				fmt.Fprintf(w, "ok\n")
-			}
+	return (&Runner{Parallel: 4}).HandlerWithCheck(h, path, fsys, glob)
+}
+
+// HandlerWithCheck is like the top-level HandlerWithCheck
+// but uses r to run the scripts.
+// The results are served by Results.ServeHTTP.
+func (r *Runner) HandlerWithCheck(h http.Handler, path string, fsys fs.FS, glob string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != path {
+			h.ServeHTTP(w, req)
 			return
 		}
-		h.ServeHTTP(w, r)
+		results, err := r.RunHandler(fsys, glob, h)
+		if err != nil {
+			http.Error(w, "webtest: "+err.Error()+"\n", http.StatusInternalServerError)
+			return
+		}
+		results.ServeHTTP(w, req)
 	})
 }
 
+// ServeHTTP serves a report of rs, with status code 200 if all the cases
+// passed and 500 (internal server error) if any failed.
+// The query parameter format=json or format=junit selects a report
+// written by Results.WriteJSON or Results.WriteJUnit instead of plain text.
+func (rs Results) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	code := http.StatusOK
+	if rs.Failed() > 0 {
+		code = http.StatusInternalServerError
+	}
+	switch req.FormValue("format") {
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		rs.WriteJSON(w)
+	case "junit":
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(code)
+		rs.WriteJUnit(w)
+	default:
+		if err := rs.Err(); err != nil {
+			http.Error(w, "webtest.CheckHandler failed:\n"+err.Error()+"\n", code)
+			return
+		}
+		fmt.Fprintf(w, "ok\n")
+	}
+}
+
 // CheckHandler runs the test script files in fsys matched by glob
 // against the handler h. If any errors are encountered,
 // CheckHandler returns an error listing the problems.
+// It runs the scripts one at a time; to run them concurrently, use a Runner.
 func CheckHandler(fsys fs.FS, glob string, h http.Handler) error {
-	return check(fsys, glob, func(c *case_) error { return c.runHandler(h) })
-}
-
-func check(fsys fs.FS, glob string, do func(*case_) error) error {
-	files, err := fs.Glob(fsys, glob)
+	results, err := new(Runner).RunHandler(fsys, glob, h)
 	if err != nil {
 		return err
 	}
-	if len(files) == 0 {
-		return fmt.Errorf("no files match %#q", glob)
-	}
-	var buf bytes.Buffer
-	for _, file := range files {
-		data, err := fs.ReadFile(fsys, file)
-		if err != nil {
-			fmt.Fprintf(&buf, "# %s\n%v\n", file, err)
-			continue
-		}
-		script, err := parseScript(file, string(data))
-		if err != nil {
-			fmt.Fprintf(&buf, "# %s\n%v\n", file, err)
-			continue
-		}
-		hdr := false
-		for _, c := range script.cases {
-			if err := do(c); err != nil {
-				if !hdr {
-					fmt.Fprintf(&buf, "# %s\n", file)
-					hdr = true
-				}
-				fmt.Fprintf(&buf, "## %s %s\n", c.method, c.url)
-				fmt.Fprintf(&buf, "%v\n", err)
-			}
-		}
-	}
-	if buf.Len() > 0 {
-		return errors.New(buf.String())
-	}
-	return nil
+	return results.Err()
 }
 
 // TestHandler runs the test script files matched by glob
 // against the handler h.
 func TestHandler(t *testing.T, glob string, h http.Handler) {
-	test(t, glob, func(c *case_) error { return c.runHandler(h) })
+	new(Runner).TestHandler(t, glob, h)
+}
+
+// TestHandler is like the top-level TestHandler,
+// but if r.Update is set, it writes snapshot files instead of checking them.
+// Like the top-level TestHandler, it runs the cases one at a time,
+// ignoring r.Parallel.
+func (r *Runner) TestHandler(t *testing.T, glob string, h http.Handler) {
+	updateDir := func(file string) string {
+		if r.Update {
+			return filepath.Dir(file)
+		}
+		return ""
+	}
+	test(t, glob, updateDir, func(c *case_) error { return c.runHandler(h) })
 }
 
-func test(t *testing.T, glob string, do func(*case_) error) {
+// test runs the test script files matched by glob, calling do to run each case.
+// Snapshot files for the script file are written in updateDir(file),
+// or checked if that is "".
+func test(t *testing.T, glob string, updateDir func(string) string, do func(*case_) error) {
 	files, err := filepath.Glob(glob)
 	if err != nil {
 		t.Fatal(err)
@@ -254,6 +411,7 @@ func test(t *testing.T, glob string, do func(*case_) error) {
 			if err != nil {
 				t.Fatal(err)
 			}
+			script.setFiles(os.DirFS(filepath.Dir(file)), updateDir(file))
 			for _, c := range script.cases {
 				t.Run(c.method+"/"+strings.TrimPrefix(c.url, "/"), func(t *testing.T) {
 					if err := do(c); err != nil {
@@ -266,11 +424,25 @@ func test(t *testing.T, glob string, do func(*case_) error) {
 }
 
 // A script is a parsed test script.
+// It also holds the state shared by its cases as they run.
 type script struct {
 	cases []*case_
+	vars  map[string]string // variables set by capture
+	jar   *cookiejar.Jar     // cookies set by responses
+}
+
+// setFiles sets the file system holding the files named in the script,
+// which is rooted at the script's directory. If updateDir is not empty,
+// it is the script's directory in the operating system's file system,
+// and snapshot checks write their snapshot files there instead of checking them.
+func (s *script) setFiles(fsys fs.FS, updateDir string) {
+	for _, c := range s.cases {
+		c.fsys = fsys
+		c.updateDir = updateDir
+	}
 }
 
-// A case_ is a single test case (GET/HEAD/POST) in a script.
+// A case_ is a single test case (a request and its checks) in a script.
 type case_ struct {
 	file      string
 	line      int
@@ -279,8 +451,22 @@ type case_ struct {
 	postbody  string
 	postquery string
 	posttype  string
+	bodyFile  string // file holding the request body, from body @file
+	multipart string // multipart form fields, from multipart
 	hint      string
 	checks    []*cmpCheck
+	captures  []*cmpCheck
+	headers   []keyValue // request headers
+	cookies   []keyValue // request cookies
+	follow    bool       // follow redirects
+	script    *script
+	fsys      fs.FS  // files named in the script, relative to its directory
+	updateDir string // directory in which to write snapshots, or "" to check them
+}
+
+// A keyValue is a request header or cookie.
+type keyValue struct {
+	key, value string
 }
 
 // A cmp is a single comparison (check) made against a test case.
@@ -292,17 +478,26 @@ type cmpCheck struct {
 	op      string
 	want    string
 	wantRE  *regexp.Regexp
+	sel     selector // for html
+	path    jsonPath // for json
+	capture string   // for a capture, the variable name
 }
 
 // runHandler runs a test case against the handler h.
 func (c *case_) runHandler(h http.Handler) error {
-	w := httptest.NewRecorder()
-	r, err := c.newRequest(c.url)
+	u, err := c.expand(c.url)
 	if err != nil {
-		return err
+		return fmt.Errorf("%s:%d: %s %s: %s", c.file, c.line, c.method, c.url, err)
 	}
-	h.ServeHTTP(w, r)
-	return c.check(w.Result(), w.Body.String())
+	return c.run(u, func(r *http.Request) (*http.Response, error) {
+		if r.Body == nil {
+			// As in server requests, the body is always non-nil.
+			r.Body = http.NoBody
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Result(), nil
+	})
 }
 
 // runServer runs a test case against the server at address addr.
@@ -320,18 +515,17 @@ func (c *case_) runServer(addr string) error {
 	}
 
 	// Build full URL for request.
-	u := c.url
+	cu, err := c.expand(c.url)
+	if err != nil {
+		return fmt.Errorf("%s:%d: %s %s: %s", c.file, c.line, c.method, c.url, err)
+	}
+	u := cu
 	if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
 		u = strings.TrimSuffix(baseURL, "/")
-		if !strings.HasPrefix(c.url, "/") {
+		if !strings.HasPrefix(cu, "/") {
 			u += "/"
 		}
-		u += c.url
-	}
-	req, err := c.newRequest(u)
-
-	if err != nil {
-		return fmt.Errorf("%s:%d: %s %s: %s", c.file, c.line, c.method, c.url, err)
+		u += cu
 	}
 	tr := &http.Transport{}
 	if !strings.HasPrefix(u, baseURL) {
@@ -343,67 +537,337 @@ func (c *case_) runServer(addr string) error {
 		}
 		tr.Proxy = func(*http.Request) (*url.URL, error) { return proxyURL, nil }
 	}
-	resp, err := tr.RoundTrip(req)
+	return c.run(u, tr.RoundTrip)
+}
+
+// maxRedirects is the maximum number of redirects followed by a case using “follow”.
+const maxRedirects = 10
+
+// run runs a test case, sending its request for the URL u using roundTrip,
+// following redirects if the case asks to, and then checking the final response.
+func (c *case_) run(u string, roundTrip func(*http.Request) (*http.Response, error)) error {
+	method := c.method
+	body, typ, err := c.requestBody()
 	if err != nil {
 		return fmt.Errorf("%s:%d: %s %s: %s", c.file, c.line, c.method, c.url, err)
 	}
-	body, err := ioutil.ReadAll(resp.Body)
-	resp.Body.Close()
-	if err != nil {
-		return fmt.Errorf("%s:%d: %s %s: reading body: %s", c.file, c.line, c.method, c.url, err)
+	var chain []string
+	start := time.Now()
+	for {
+		req, err := c.newRequest(method, u, body, typ)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %s %s: %s", c.file, c.line, c.method, c.url, err)
+		}
+		resp, err := roundTrip(req)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %s %s: %s", c.file, c.line, c.method, c.url, err)
+		}
+		data, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("%s:%d: %s %s: reading body: %s", c.file, c.line, c.method, c.url, err)
+		}
+		c.script.jar.SetCookies(cookieURL(req.URL), resp.Cookies())
+
+		loc := resp.Header.Get("Location")
+		if len(chain) == 0 {
+			chain = append(chain, c.url)
+		}
+		if !c.follow || resp.StatusCode/100 != 3 || loc == "" {
+			return c.check(&response{Response: resp, body: string(data), chain: chain, elapsed: time.Since(start)})
+		}
+		chain = append(chain, loc)
+		if len(chain) > maxRedirects+1 {
+			return fmt.Errorf("%s:%d: %s %s: stopped after %d redirects", c.file, c.line, c.method, c.url, maxRedirects)
+		}
+		next, err := req.URL.Parse(loc)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %s %s: bad redirect: %s", c.file, c.line, c.method, c.url, err)
+		}
+		u = next.String()
+		// Like browsers, switch to GET except for 307 and 308 redirects.
+		if resp.StatusCode != http.StatusTemporaryRedirect && resp.StatusCode != http.StatusPermanentRedirect && method != "HEAD" {
+			method = "GET"
+			body, typ = "", ""
+		}
 	}
-	return c.check(resp, string(body))
 }
 
 // newRequest creates a new request for the case c,
-// using the URL u.
-func (c *case_) newRequest(u string) (*http.Request, error) {
-	body := c.requestBody()
-	r, err := http.NewRequest(c.method, u, body)
+// using the method, URL u, and body, which has content type typ.
+func (c *case_) newRequest(method, u, body, typ string) (*http.Request, error) {
+	var rbody io.Reader
+	if body != "" {
+		rbody = strings.NewReader(body)
+	}
+	r, err := http.NewRequest(method, u, rbody)
 	if err != nil {
 		return nil, err
 	}
-	typ := c.posttype
-	if body != nil && typ == "" {
-		typ = "application/x-www-form-urlencoded"
-	}
-	if typ != "" {
+	if body != "" {
 		r.Header.Set("Content-Type", typ)
 	}
+	for _, h := range c.headers {
+		v, err := c.expand(h.value)
+		if err != nil {
+			return nil, err
+		}
+		r.Header.Add(h.key, v)
+	}
+	for _, ck := range c.script.jar.Cookies(cookieURL(r.URL)) {
+		r.AddCookie(ck)
+	}
+	for _, ck := range c.cookies {
+		v, err := c.expand(ck.value)
+		if err != nil {
+			return nil, err
+		}
+		r.AddCookie(&http.Cookie{Name: ck.key, Value: v})
+	}
 	return r, nil
 }
 
-// requestBody returns the body for the case's request.
-func (c *case_) requestBody() io.Reader {
-	if c.postbody == "" {
-		return nil
+// cookieURL returns the URL u made absolute, for use with a cookie jar.
+// Requests to handlers often have URLs without a host.
+func cookieURL(u *url.URL) *url.URL {
+	if u.Host != "" {
+		return u
+	}
+	abs := *u
+	abs.Scheme = "http"
+	abs.Host = "example.com"
+	return &abs
+}
+
+// requestBody returns the body for the case's request,
+// with variables expanded, along with its content type.
+// The content type is c.posttype if set, or else a default
+// that depends on how the body is specified.
+func (c *case_) requestBody() (body, typ string, err error) {
+	switch {
+	default:
+		body, err = c.expand(c.postbody)
+		typ = "application/x-www-form-urlencoded"
+	case c.postquery != "":
+		body, err = c.queryBody()
+		typ = "application/x-www-form-urlencoded"
+	case c.bodyFile != "":
+		var data []byte
+		data, err = fs.ReadFile(c.fsys, c.bodyFile)
+		body = string(data)
+		typ = fileType(c.bodyFile)
+	case c.multipart != "":
+		body, typ, err = c.multipartBody()
 	}
-	return strings.NewReader(c.postbody)
+	if c.posttype != "" {
+		typ = c.posttype
+	}
+	return body, typ, err
+}
+
+// queryBody returns the query-encoded body specified by c.postquery.
+func (c *case_) queryBody() (string, error) {
+	var body string
+	for _, kv := range strings.Split(c.postquery, "\n") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+		k, v, _ := cut(kv, "=")
+		v, err := c.expand(v)
+		if err != nil {
+			return "", err
+		}
+		if body != "" {
+			body += "&"
+		}
+		body += url.QueryEscape(k) + "=" + url.QueryEscape(v)
+	}
+	return body, nil
+}
+
+// multipartBody returns the multipart form body specified by c.multipart,
+// along with its content type, which includes the part boundary.
+func (c *case_) multipartBody() (body, typ string, err error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for _, kv := range strings.Split(c.multipart, "\n") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+		k, v, _ := cut(kv, "=")
+		if strings.HasPrefix(v, "@") {
+			file := v[1:]
+			data, err := fs.ReadFile(c.fsys, file)
+			if err != nil {
+				return "", "", err
+			}
+			h := make(textproto.MIMEHeader)
+			h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, quoteEscaper.Replace(k), quoteEscaper.Replace(path.Base(file))))
+			h.Set("Content-Type", fileType(file))
+			w, err := mw.CreatePart(h)
+			if err != nil {
+				return "", "", err
+			}
+			w.Write(data)
+			continue
+		}
+		v, err := c.expand(v)
+		if err != nil {
+			return "", "", err
+		}
+		if err := mw.WriteField(k, v); err != nil {
+			return "", "", err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return "", "", err
+	}
+	return buf.String(), mw.FormDataContentType(), nil
+}
+
+// quoteEscaper escapes quoted strings in a Content-Disposition header,
+// like mime/multipart's unexported escapeQuotes.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// fileType returns the content type for sending the named file,
+// based on its extension.
+func fileType(file string) string {
+	if typ := mime.TypeByExtension(path.Ext(file)); typ != "" {
+		return typ
+	}
+	return "application/octet-stream"
+}
+
+var varRE = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expand returns text with each ${name} replaced by the value
+// of the variable captured with that name.
+func (c *case_) expand(text string) (string, error) {
+	return c.expandFunc(text, func(s string) string { return s })
+}
+
+// expandFunc is like expand but passes each value through quote first.
+func (c *case_) expandFunc(text string, quote func(string) string) (string, error) {
+	var err error
+	text = varRE.ReplaceAllStringFunc(text, func(v string) string {
+		name := v[2 : len(v)-1]
+		val, ok := c.script.vars[name]
+		if !ok {
+			if err == nil {
+				err = fmt.Errorf("undefined variable %s", v)
+			}
+			return v
+		}
+		return quote(val)
+	})
+	return text, err
+}
+
+// A response is the response to a case's request, as seen by its checks.
+type response struct {
+	*http.Response
+	body    string
+	chain   []string      // URLs requested, when following redirects
+	elapsed time.Duration // time taken to make the requests and read the responses
+	doc     *html.Node    // parsed body, for html values; set by value
+}
+
+// capture runs the case's captures against the response,
+// setting the script variables they name.
+func (c *case_) capture(r *response) error {
+	for _, cap := range c.captures {
+		value, err := c.value(cap, r)
+		if err != nil {
+			return fmt.Errorf("%s:%d: capture %s: %v", cap.file, cap.line, cap.capture, err)
+		}
+		if cap.wantRE != nil {
+			m := cap.wantRE.FindStringSubmatch(value)
+			if m == nil {
+				return fmt.Errorf("%s:%d: capture %s: %s does not match %#q\n\t%s", cap.file, cap.line, cap.capture, cap.what, cap.want, indent(value))
+			}
+			value = m[len(m)-1]
+		}
+		c.script.vars[cap.capture] = value
+	}
+	return nil
+}
+
+// value returns the value checked or captured by chk in the response.
+// The html check parses the body into r.doc, if r.doc is nil,
+// so that later checks can reuse it.
+func (c *case_) value(chk *cmpCheck, r *response) (string, error) {
+	switch chk.what {
+	case "json":
+		return chk.path.value(r.body)
+	case "html":
+		if r.doc == nil {
+			var err error
+			if r.doc, err = html.Parse(strings.NewReader(r.body)); err != nil {
+				return "", fmt.Errorf("parsing body: %v", err)
+			}
+		}
+		nodes := selectAll(r.doc, chk.sel)
+		if chk.op == "count" {
+			return fmt.Sprint(len(nodes)), nil
+		}
+		var texts []string
+		for _, n := range nodes {
+			texts = append(texts, nodeText(n))
+		}
+		return strings.Join(texts, "\n"), nil
+	case "body":
+		return r.body, nil
+	case "trimbody":
+		return trim(r.body), nil
+	case "code":
+		return fmt.Sprint(r.StatusCode), nil
+	case "header":
+		return r.Header.Get(chk.whatArg), nil
+	case "redirect":
+		if r.StatusCode/10 == 30 {
+			return r.Header.Get("Location"), nil
+		}
+		return "", nil
+	case "chain":
+		return strings.Join(r.chain, "\n") + "\n", nil
+	case "time":
+		return r.elapsed.Round(time.Microsecond).String(), nil
+	}
+	return "unknown what: " + chk.what, nil
 }
 
 // check checks the response against the comparisons for the case.
-func (c *case_) check(resp *http.Response, body string) error {
+func (c *case_) check(r *response) error {
 	var msg bytes.Buffer
+	if err := c.capture(r); err != nil {
+		fmt.Fprintf(&msg, "%v\n", err)
+	}
 	for _, chk := range c.checks {
 		what := chk.what
 		if chk.whatArg != "" {
 			what += " " + chk.whatArg
 		}
-		var value string
-		switch chk.what {
-		default:
-			value = "unknown what: " + chk.what
-		case "body":
-			value = body
-		case "trimbody":
-			value = trim(body)
-		case "code":
-			value = fmt.Sprint(resp.StatusCode)
-		case "header":
-			value = resp.Header.Get(chk.whatArg)
-		case "redirect":
-			if resp.StatusCode/10 == 30 {
-				value = resp.Header.Get("Location")
+		value, err := c.value(chk, r)
+		if err != nil {
+			fmt.Fprintf(&msg, "%s:%d: %s: %v\n", chk.file, chk.line, what, err)
+			continue
+		}
+
+		want, wantRE := chk.want, chk.wantRE
+		if varRE.MatchString(want) {
+			quote := func(s string) string { return s }
+			if wantRE != nil {
+				quote = regexp.QuoteMeta
+			}
+			want, err = c.expandFunc(want, quote)
+			if err != nil {
+				fmt.Fprintf(&msg, "%s:%d: %v\n", chk.file, chk.line, err)
+				continue
+			}
+			if wantRE != nil {
+				wantRE = regexp.MustCompile(`(?m)` + want)
 			}
 		}
 
@@ -411,28 +875,45 @@ func (c *case_) check(resp *http.Response, body string) error {
 		default:
 			fmt.Fprintf(&msg, "%s:%d: unknown operator %s\n", chk.file, chk.line, chk.op)
 		case "==":
-			if value != chk.want {
-				fmt.Fprintf(&msg, "%s:%d: %s = %q, want %q\n", chk.file, chk.line, what, value, chk.want)
+			if value != want {
+				fmt.Fprintf(&msg, "%s:%d: %s = %q, want %q\n", chk.file, chk.line, what, value, want)
 			}
 		case "!=":
-			if value == chk.want {
+			if value == want {
 				fmt.Fprintf(&msg, "%s:%d: %s == %q (but want !=)\n", chk.file, chk.line, what, value)
 			}
 		case "~":
-			if !chk.wantRE.MatchString(value) {
-				fmt.Fprintf(&msg, "%s:%d: %s does not match %#q (but should)\n\t%s\n", chk.file, chk.line, what, chk.want, indent(value))
+			if !wantRE.MatchString(value) {
+				fmt.Fprintf(&msg, "%s:%d: %s does not match %#q (but should)\n\t%s\n", chk.file, chk.line, what, want, indent(value))
 			}
 		case "!~":
-			if chk.wantRE.MatchString(value) {
-				fmt.Fprintf(&msg, "%s:%d: %s matches %#q (but should not)\n\t%s\n", chk.file, chk.line, what, chk.want, indent(value))
+			if wantRE.MatchString(value) {
+				fmt.Fprintf(&msg, "%s:%d: %s matches %#q (but should not)\n\t%s\n", chk.file, chk.line, what, want, indent(value))
 			}
 		case "contains":
-			if !strings.Contains(value, chk.want) {
-				fmt.Fprintf(&msg, "%s:%d: %s does not contain %#q (but should)\n\t%s\n", chk.file, chk.line, what, chk.want, indent(value))
+			if !strings.Contains(value, want) {
+				fmt.Fprintf(&msg, "%s:%d: %s does not contain %#q (but should)\n\t%s\n", chk.file, chk.line, what, want, indent(value))
 			}
 		case "!contains":
-			if strings.Contains(value, chk.want) {
-				fmt.Fprintf(&msg, "%s:%d: %s contains %#q (but should not)\n\t%s\n", chk.file, chk.line, what, chk.want, indent(value))
+			if strings.Contains(value, want) {
+				fmt.Fprintf(&msg, "%s:%d: %s contains %#q (but should not)\n\t%s\n", chk.file, chk.line, what, want, indent(value))
+			}
+		case "count":
+			if value != want {
+				fmt.Fprintf(&msg, "%s:%d: %s matches %s elements, want %s\n", chk.file, chk.line, what, value, want)
+			}
+		case "snapshot":
+			if err := c.checkSnapshot(want, value); err != nil {
+				fmt.Fprintf(&msg, "%s:%d: %s %v\n", chk.file, chk.line, what, err)
+			}
+		case "<", ">":
+			limit, err := time.ParseDuration(want)
+			if err != nil {
+				fmt.Fprintf(&msg, "%s:%d: invalid duration %q\n", chk.file, chk.line, want)
+				break
+			}
+			if chk.op == "<" && r.elapsed >= limit || chk.op == ">" && r.elapsed <= limit {
+				fmt.Fprintf(&msg, "%s:%d: %s = %s, want %s %s\n", chk.file, chk.line, what, value, chk.op, want)
 			}
 		}
 	}
@@ -446,6 +927,39 @@ func (c *case_) check(resp *http.Response, body string) error {
 	return nil
 }
 
+// checkSnapshot checks that value matches the contents of the snapshot file.
+// If the case has an update directory, checkSnapshot instead writes value
+// to the file in that directory.
+func (c *case_) checkSnapshot(file, value string) error {
+	if c.updateDir != "" {
+		return ioutil.WriteFile(filepath.Join(c.updateDir, filepath.FromSlash(file)), []byte(value), 0666)
+	}
+	if c.fsys == nil {
+		return fmt.Errorf("cannot read snapshot %s", file)
+	}
+	data, err := fs.ReadFile(c.fsys, file)
+	if err != nil {
+		return fmt.Errorf("reading snapshot: %v", err)
+	}
+	want := string(data)
+	if value == want {
+		return nil
+	}
+	// Report the first differing line.
+	have, wantLines := strings.SplitAfter(value, "\n"), strings.SplitAfter(want, "\n")
+	i := 0
+	for i < len(have) && i < len(wantLines) && have[i] == wantLines[i] {
+		i++
+	}
+	line := func(lines []string) string {
+		if i >= len(lines) {
+			return "(end of text)"
+		}
+		return strconv.Quote(lines[i])
+	}
+	return fmt.Errorf("does not match snapshot %s at line %d (run with Runner.Update set to accept):\n\thave %s\n\twant %s", file, i+1, line(have), line(wantLines))
+}
+
 // trim returns a trimming of s, in which all runs of spaces and tabs have
 // been collapsed to a single space, leading and trailing spaces have been
 // removed from each line, and blank lines are removed entirely.
@@ -480,7 +994,8 @@ func parseScript(file, text string) (*script, error) {
 		Case      *case_
 		Multiline *string
 	}
-	script := new(script)
+	jar, _ := cookiejar.New(nil)
+	script := &script{vars: make(map[string]string), jar: jar}
 	lastLineWasBlank := true
 	lineno := 0
 	line := ""
@@ -525,14 +1040,14 @@ func parseScript(file, text string) (*script, error) {
 
 		// Look for start of new check.
 		switch what {
-		case "GET", "HEAD", "POST":
+		case "GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS":
 			if !lastLineWasBlank {
 				return nil, errorf("missing blank line before start of case")
 			}
 			if args == "" {
 				return nil, errorf("missing %s URL", what)
 			}
-			cas := &case_{method: what, url: args, file: file, line: lineno}
+			cas := &case_{method: what, url: args, file: file, line: lineno, script: script}
 			script.cases = append(script.cases, cas)
 			current.Case = cas
 			lastLineWasBlank = false
@@ -540,7 +1055,7 @@ func parseScript(file, text string) (*script, error) {
 		}
 
 		if lastLineWasBlank || current.Case == nil {
-			return nil, errorf("missing GET/HEAD/POST at start of check")
+			return nil, errorf("missing request (GET, POST, and so on) at start of check")
 		}
 
 		// Look for case metadata.
@@ -552,12 +1067,23 @@ func parseScript(file, text string) (*script, error) {
 			targ = &current.Case.postquery
 		case "posttype":
 			targ = &current.Case.posttype
+		case "multipart":
+			targ = &current.Case.multipart
+		case "body":
+			// body @file is the request body; otherwise body is a check.
+			if strings.HasPrefix(args, "@") {
+				if args == "@" {
+					return nil, errorf("missing file name after @")
+				}
+				targ = &current.Case.bodyFile
+				args = args[1:]
+			}
 		case "hint":
 			targ = &current.Case.hint
 		}
 		if targ != nil {
-			if strings.HasPrefix(what, "post") && current.Case.method != "POST" {
-				return nil, errorf("need POST (not %v) for %v", current.Case.method, what)
+			if targ != &current.Case.hint && (current.Case.method == "GET" || current.Case.method == "HEAD") {
+				return nil, errorf("%v request cannot have %v", current.Case.method, what)
 			}
 			if args != "" {
 				*targ = args
@@ -567,27 +1093,123 @@ func parseScript(file, text string) (*script, error) {
 			continue
 		}
 
-		// Start a comparison check.
+		// Look for request headers, cookies, and redirect following.
+		switch what {
+		case "header":
+			if key, value := splitOneField(args); strings.HasSuffix(key, ":") {
+				// header Key: value, not a header check.
+				current.Case.headers = append(current.Case.headers, keyValue{strings.TrimSuffix(key, ":"), value})
+				continue
+			}
+		case "cookie":
+			k, v, ok := cut(args, "=")
+			if !ok || k == "" {
+				return nil, errorf("cookie must be name=value")
+			}
+			current.Case.cookies = append(current.Case.cookies, keyValue{k, v})
+			continue
+		case "follow":
+			if args != "" {
+				return nil, errorf("unexpected text after follow")
+			}
+			current.Case.follow = true
+			continue
+		}
+
+		// Start a capture or comparison check.
 		chk := &cmpCheck{file: file, line: lineno, what: what}
-		current.Case.checks = append(current.Case.checks, chk)
+		if what == "capture" {
+			chk.capture, args = splitOneField(args)
+			if !regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`).MatchString(chk.capture) {
+				return nil, errorf("invalid capture variable name %q", chk.capture)
+			}
+			chk.what, args = splitOneField(args)
+			what = chk.what
+			current.Case.captures = append(current.Case.captures, chk)
+		} else {
+			current.Case.checks = append(current.Case.checks, chk)
+		}
 		switch what {
-		case "body", "code", "redirect":
+		case "body", "code", "redirect", "time", "trimbody":
 			// no WhatArg
+		case "chain":
+			if !current.Case.follow {
+				return nil, errorf("chain requires follow")
+			}
 		case "header":
 			chk.whatArg, args = splitOneField(args)
 			if chk.whatArg == "" {
 				return nil, errorf("missing header name")
 			}
+		case "json":
+			chk.whatArg, args = splitOneField(args)
+			if chk.whatArg == "" {
+				return nil, errorf("missing JSON path")
+			}
+			path, err := parseJSONPath(chk.whatArg)
+			if err != nil {
+				return nil, errorf("%v", err)
+			}
+			chk.path = path
+		case "html":
+			// The selector may contain spaces; it ends at the operator.
+			var sel []string
+			for {
+				f, rest := splitOneField(args)
+				// > is a CSS combinator; only time checks use it as an operator.
+				if f == "" || isCheckOp(f) && f != ">" && f != "<" {
+					break
+				}
+				sel = append(sel, f)
+				args = rest
+			}
+			chk.whatArg = strings.Join(sel, " ")
+			if chk.whatArg == "" {
+				return nil, errorf("missing CSS selector")
+			}
+			s, err := parseSelector(chk.whatArg)
+			if err != nil {
+				return nil, errorf("%v", err)
+			}
+			chk.sel = s
+		}
+
+		if chk.capture != "" {
+			// capture name <value> [~ regexp]
+			if args == "" {
+				continue
+			}
+			if chk.op, args = splitOneField(args); chk.op != "~" || args == "" {
+				return nil, errorf("capture must use ~ regexp")
+			}
+			chk.want = args
+			continue
 		}
 
 		// Opcode, with optional leading "not"
 		chk.op, args = splitOneField(args)
-		switch chk.op {
-		case "==", "!=", "~", "!~", "contains", "!contains":
-			// ok
-		default:
+		if !isCheckOp(chk.op) {
 			return nil, errorf("unknown check operator %q", chk.op)
 		}
+		if chk.op == "count" {
+			if what != "html" {
+				return nil, errorf("count requires html check")
+			}
+			if _, err := strconv.Atoi(args); err != nil {
+				return nil, errorf("invalid count %q", args)
+			}
+		}
+		if chk.op == "snapshot" && args == "" {
+			return nil, errorf("missing snapshot file name")
+		}
+		if (chk.op == "<" || chk.op == ">") != (what == "time") {
+			return nil, errorf("time checks must use < or >, and only time checks can")
+		}
+		if what == "time" && !varRE.MatchString(args) {
+			if _, err := time.ParseDuration(args); err != nil {
+				return nil, errorf("invalid duration %q", args)
+			}
+		}
 
 		if args != "" {
 			chk.want = args
@@ -601,27 +1223,55 @@ func parseScript(file, text string) (*script, error) {
 	// Check that each regexp compiles, and insert "code equals 200"
 	// in each case that doesn't already have a code check.
 	for _, cas := range script.cases {
-		if cas.postquery != "" {
-			if cas.postbody != "" {
-				line = ""
-				lineno = cas.line
-				return nil, errorf("case has postbody and postquery")
+		n := 0
+		for _, b := range []string{cas.postbody, cas.postquery, cas.bodyFile, cas.multipart} {
+			if b != "" {
+				n++
+			}
+		}
+		if n > 1 {
+			line = ""
+			lineno = cas.line
+			return nil, errorf("case has more than one of postbody, postquery, body @file, and multipart")
+		}
+		if cas.multipart != "" && cas.posttype != "" {
+			line = ""
+			lineno = cas.line
+			return nil, errorf("case has multipart and posttype")
+		}
+		for _, kv := range strings.Split(cas.multipart, "\n") {
+			if kv = strings.TrimSpace(kv); kv == "" {
+				continue
 			}
+			if k, _, ok := cut(kv, "="); !ok || k == "" {
+				lineno = cas.line // close enough
+				line = kv
+				return nil, errorf("multipart has non key=value line")
+			}
+		}
+		if cas.postquery != "" {
+			// The body is computed by requestBody, after expanding variables.
 			for _, kv := range strings.Split(cas.postquery, "\n") {
 				kv = strings.TrimSpace(kv)
 				if kv == "" {
 					continue
 				}
-				k, v, ok := cut(kv, "=")
-				if !ok {
+				if _, _, ok := cut(kv, "="); !ok {
 					lineno = cas.line // close enough
 					line = kv
 					return nil, errorf("postquery has non key=value line")
 				}
-				if cas.postbody != "" {
-					cas.postbody += "&"
+			}
+		}
+		for _, chk := range cas.captures {
+			if chk.op == "~" {
+				re, err := regexp.Compile(`(?m)` + chk.want)
+				if err != nil {
+					lineno = chk.line
+					line = chk.want
+					return nil, errorf("invalid regexp: %s", err)
 				}
-				cas.postbody += url.QueryEscape(k) + "=" + url.QueryEscape(v)
+				chk.wantRE = re
 			}
 		}
 		sawCode := false
@@ -629,8 +1279,14 @@ func parseScript(file, text string) (*script, error) {
 			if chk.what == "code" || chk.what == "redirect" {
 				sawCode = true
 			}
+			if chk.what == "html" || chk.what == "json" {
+				// Values never end in newlines; multiline texts always do.
+				chk.want = strings.TrimSuffix(chk.want, "\n")
+			}
 			if chk.op == "~" || chk.op == "!~" {
-				re, err := regexp.Compile(`(?m)` + chk.want)
+				// Variables are expanded when the check runs;
+				// check the regexp with them expanded to empty strings.
+				re, err := regexp.Compile(`(?m)` + varRE.ReplaceAllString(chk.want, ""))
 				if err != nil {
 					lineno = chk.line
 					line = chk.want
@@ -651,6 +1307,15 @@ func parseScript(file, text string) (*script, error) {
 	return script, nil
 }
 
+// isCheckOp reports whether op is a check operator.
+func isCheckOp(op string) bool {
+	switch op {
+	case "==", "!=", "~", "!~", "contains", "!contains", "count", "snapshot", "<", ">":
+		return true
+	}
+	return false
+}
+
 // cut returns the result of cutting s around the first instance of sep.
 func cut(s, sep string) (before, after string, ok bool) {
 	if i := strings.Index(s, sep); i >= 0 {
@@ -5,12 +5,20 @@
 package webtest
 
 import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"io/ioutil"
+	"mime"
 	"net/http"
+	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
+	"time"
 )
 
 func TestWebtestHandler(t *testing.T) {
@@ -36,6 +44,81 @@ func TestEchoHandler(t *testing.T) {
 	TestHandler(t, "testdata/echo.txt", http.HandlerFunc(echo))
 }
 
+// stateful returns a handler for testing captures, cookies, headers, and redirects.
+func stateful() http.Handler {
+	var mu sync.Mutex
+	shared := make(map[string]string)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/share", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		id := fmt.Sprintf("x%d", len(shared)+1)
+		shared[id] = r.FormValue("text")
+		fmt.Fprintf(w, "%s\n", id)
+	})
+	mux.HandleFunc("/shared/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		text, ok := shared[strings.TrimPrefix(r.URL.Path, "/shared/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, "%s\n", text)
+	})
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: r.FormValue("user"), Path: "/"})
+		http.Redirect(w, r, "/whoami", http.StatusSeeOther)
+	})
+	mux.HandleFunc("/whoami", func(w http.ResponseWriter, r *http.Request) {
+		for _, c := range r.Cookies() {
+			fmt.Fprintf(w, "%s=%s\n", c.Name, c.Value)
+		}
+	})
+	mux.HandleFunc("/headers", func(w http.ResponseWriter, r *http.Request) {
+		for _, k := range []string{"Accept-Language", "X-Token"} {
+			fmt.Fprintf(w, "%s: %s\n", k, r.Header.Get(k))
+		}
+	})
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/b", http.StatusFound)
+	})
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/c?from="+r.Method, http.StatusTemporaryRedirect)
+	})
+	mux.HandleFunc("/c", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s\n", r.Method, r.URL)
+	})
+	mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		typ, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		fmt.Fprintf(w, "%s %s\n", r.Method, typ)
+		if typ != "multipart/form-data" {
+			data, _ := ioutil.ReadAll(r.Body)
+			fmt.Fprintf(w, "%d bytes\n%s", len(data), data)
+			return
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			fmt.Fprintf(w, "%v\n", err)
+			return
+		}
+		for k, v := range r.MultipartForm.Value {
+			fmt.Fprintf(w, "%s=%s\n", k, v[0])
+		}
+		for k, v := range r.MultipartForm.File {
+			fmt.Fprintf(w, "%s: %s, %d bytes, %s\n", k, v[0].Filename, v[0].Size, v[0].Header.Get("Content-Type"))
+		}
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	return mux
+}
+
+func TestStatefulHandler(t *testing.T) {
+	h := stateful()
+	testWebtest(t, "testdata/[su][tp]*.txt", func(c *case_) error { return c.runHandler(h) })
+}
+
 func testWebtest(t *testing.T, glob string, do func(*case_) error) {
 	files, err := filepath.Glob(glob)
 	if err != nil {
@@ -51,6 +134,7 @@ func testWebtest(t *testing.T, glob string, do func(*case_) error) {
 			if err != nil {
 				t.Fatal(err)
 			}
+			script.setFiles(os.DirFS(filepath.Dir(file)), "")
 			for _, c := range script.cases {
 				t.Run(c.method+"/"+strings.TrimPrefix(c.url, "/"), func(t *testing.T) {
 					hint := c.hint
@@ -72,3 +156,114 @@ func testWebtest(t *testing.T, glob string, do func(*case_) error) {
 		})
 	}
 }
+
+func TestSnapshotUpdate(t *testing.T) {
+	dir := t.TempDir()
+	script := "GET /hello.html\nbody snapshot hello.golden\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "snap.txt"), []byte(script), 0666); err != nil {
+		t.Fatal(err)
+	}
+	h := http.FileServer(http.Dir("testdata"))
+
+	(&Runner{Update: true}).TestHandler(t, filepath.Join(dir, "snap.txt"), h)
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "hello.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "<!DOCTYPE html>\nhello, world\n"; string(data) != want {
+		t.Fatalf("hello.golden = %q, want %q", data, want)
+	}
+	if err := CheckHandler(os.DirFS(dir), "snap.txt", h); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunner(t *testing.T) {
+	var mu sync.Mutex
+	active, maxActive := 0, 0
+	var seen []string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.URL.Path)
+		active++
+		if maxActive < active {
+			maxActive = active
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		active--
+		mu.Unlock()
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, "hello\n")
+	})
+	fsys := fstest.MapFS{
+		"a.txt":   {Data: []byte("GET /1\n\nGET /2\n\nGET /3\n\nGET /missing\n")},
+		"b.txt":   {Data: []byte("GET /4\ntime < 1m\n\nGET /5\n")},
+		"bad.txt": {Data: []byte("GET /6\nbody <= x\n")},
+		"c.txt":   {Data: []byte("GET /7\n")},
+	}
+
+	r := &Runner{Parallel: 2}
+	results, err := r.RunHandler(fsys, "*.txt", h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if maxActive != 2 {
+		t.Errorf("ran %d cases at once, want 2", maxActive)
+	}
+	var inA []string
+	for _, p := range seen {
+		if p == "/1" || p == "/2" || p == "/3" || p == "/missing" {
+			inA = append(inA, p)
+		}
+	}
+	if got := strings.Join(inA, " "); got != "/1 /2 /3 /missing" {
+		t.Errorf("a.txt cases ran in order %s, want /1 /2 /3 /missing", got)
+	}
+	if len(results) != 8 || results.Failed() != 2 {
+		t.Fatalf("got %d results, %d failed, want 8, 2", len(results), results.Failed())
+	}
+	for i, url := range []string{"/1", "/2", "/3", "/missing", "/4", "/5", "", "/7"} {
+		if results[i].URL != url {
+			t.Errorf("results[%d].URL = %q, want %q", i, results[i].URL, url)
+		}
+	}
+	if err := results.Err(); err == nil || !strings.Contains(err.Error(), "# a.txt\n## GET /missing\n") || !strings.Contains(err.Error(), "# bad.txt\nbad.txt:2: ") {
+		t.Errorf("Err() = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := results.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var js struct {
+		Total, Failed int
+		Results       []struct {
+			URL  string
+			Pass bool
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &js); err != nil {
+		t.Fatal(err)
+	}
+	if js.Total != 8 || js.Failed != 2 || js.Results[3].URL != "/missing" || js.Results[3].Pass || !js.Results[4].Pass {
+		t.Errorf("WriteJSON:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := results.WriteJUnit(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var ju junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &ju); err != nil {
+		t.Fatal(err)
+	}
+	if ju.Tests != 8 || ju.Failures != 2 || len(ju.Suites) != 4 || ju.Suites[0].Name != "a.txt" || ju.Suites[0].Cases[3].Failure == nil {
+		t.Errorf("WriteJUnit:\n%s", buf.String())
+	}
+}
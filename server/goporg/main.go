@@ -8,13 +8,20 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"io/fs"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"time"
 
+	"github.com/goplus/website/internal/backport/html/template"
+	"github.com/goplus/website/internal/codewalk"
+	"github.com/goplus/website/internal/linkcheck"
+	"github.com/goplus/website/internal/metrics"
 	"github.com/goplus/website/internal/redirect"
 	"github.com/goplus/website/internal/web"
 )
@@ -22,6 +29,18 @@ import (
 var (
 	httpAddr = flag.String("http", "localhost:9999", "HTTP service address")
 	goroot   = flag.String("goroot", runtime.GOROOT(), "Go root directory")
+
+	check          = flag.Bool("check", false, "check page metadata against schema.yaml and page aliases, and exit")
+	checkCodewalks = flag.Bool("checkcodewalks", false, "check codewalk step addresses and exit")
+	checkLinks     = flag.Bool("checklinks", false, "check links and anchors in all pages and exit")
+	checkExternal  = flag.Bool("checkexternal", false, "with -checklinks, also check links to other web sites")
+
+	accessLog   = flag.Bool("accesslog", true, "write JSON access log entries to standard error")
+	metricsPath = flag.String("metrics", "", "URL path on -admin serving Prometheus metrics (empty to disable)")
+	selftest    = flag.String("selftest", "", "URL path on -admin serving the results of running the testdata scripts against the site (empty to disable)")
+
+	langs       = flag.String("langs", "en,zh", "comma-separated content languages, default first (empty for none)")
+	outputCache = flag.Int64("outputcache", 32<<20, "maximum bytes of rendered pages to cache (0 to disable)")
 )
 
 func usage() {
@@ -45,39 +64,160 @@ func main() {
 		fmt.Fprintln(os.Stderr, "Unexpected arguments.")
 		usage()
 	}
-	if *httpAddr == "" {
-		fmt.Fprintln(os.Stderr, "-http must be set")
+	if *check {
+		site := web.NewSite(os.DirFS(contentDir))
+		errs := append(site.CheckPages(), site.CheckAliases()...)
+		for _, err := range errs {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		if len(errs) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+	if *checkCodewalks {
+		fsys := siteFS(os.DirFS(contentDir), os.DirFS(*goroot))
+		if err := codewalk.CheckError(fsys, "doc/codewalk"); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if *checkLinks {
+		if !runCheckLinks(contentDir) {
+			os.Exit(1)
+		}
+		return
+	}
+	if *httpAddr == "" && *httpsAddr == "" && *unixSocket == "" {
+		fmt.Fprintln(os.Stderr, "one of -http, -https, or -unix must be set")
+		usage()
+	}
+	if *redirectHTTPS && *httpsAddr == "" {
+		fmt.Fprintln(os.Stderr, "-redirecthttps requires -https")
+		usage()
+	}
+	if (*selftest != "" || *metricsPath != "") && *adminAddr == "" {
+		fmt.Fprintln(os.Stderr, "-selftest and -metrics require -admin")
 		usage()
 	}
 
-	handler := NewHandler(contentDir, *goroot)
+	admin := http.NewServeMux()
+	handler := NewHandler(contentDir, *goroot, admin)
+	if *selftest != "" {
+		// Run the test scripts against the site itself, so that a
+		// deployment can be smoke-tested by fetching *selftest
+		// from the admin address.
+		admin.Handle(*selftest, &selfTester{
+			handler:  handler,
+			scripts:  os.DirFS(filepath.Join(repoRoot, "server/goporg/testdata")),
+			interval: time.Minute,
+		})
+	}
 
-	// Start http server.
-	fmt.Fprintf(os.Stderr, "serving http://%s\n", *httpAddr)
-	if err := http.ListenAndServe(*httpAddr, handler); err != nil {
-		log.Fatalf("ListenAndServe %s: %v", *httpAddr, err)
+	// Start servers; return after graceful shutdown.
+	if err := serve(handler, admin); err != nil {
+		log.Fatal(err)
 	}
 }
 
+// runCheckLinks checks the links in every page of the site,
+// printing the broken ones. It reports whether all links are OK.
+func runCheckLinks(contentDir string) bool {
+	content := web.NewSite(os.DirFS(contentDir))
+	if *langs != "" {
+		content.SetLanguages(strings.Split(*langs, ",")...)
+	}
+	pages, err := content.PageURLs()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return false
+	}
+
+	*accessLog = false
+	c := &linkcheck.Checker{
+		Handler: NewHandler(contentDir, *goroot, nil),
+		Hosts:   []string{"goplus.org", "www.goplus.org"},
+	}
+	if *checkExternal {
+		c.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+	broken := c.Check(pages)
+	for _, b := range broken {
+		fmt.Fprintln(os.Stderr, b)
+	}
+	return len(broken) == 0
+}
+
 // NewHandler returns the http.Handler for the web site,
 // given the directory where the content can be found
 // (can be "", in which case an internal copy is used)
 // and the directory of the GOROOT.
-func NewHandler(contentDir, goroot string) http.Handler {
+// If admin is non-nil, operator endpoints such as -metrics
+// are registered on it rather than on the site.
+func NewHandler(contentDir, goroot string, admin *http.ServeMux) http.Handler {
+	return newHandler(os.DirFS(contentDir), os.DirFS(goroot), admin)
+}
+
+// newHandler returns the http.Handler for the web site
+// serving the content and GOROOT file systems,
+// registering operator endpoints on admin if it is non-nil.
+func newHandler(contentFS, gorootFS fs.FS, admin *http.ServeMux) http.Handler {
 	mux := http.NewServeMux()
-	contentFS := os.DirFS(contentDir)
-	gorootFS := os.DirFS(goroot)
 	_, err := newSite(mux, "", contentFS, gorootFS)
 	if err != nil {
 		log.Fatalf("newSite: %v", err)
 	}
 	redirect.Register(mux)
-	return mux
+
+	rules, err := redirect.LoadRules(contentFS, "redirects.yaml")
+	if err != nil {
+		log.Fatalf("redirect rules: %v", err)
+	}
+
+	var accessLogW io.Writer
+	if *accessLog {
+		accessLogW = os.Stderr
+	}
+	m := metrics.New(accessLogW)
+	if admin != nil && *metricsPath != "" {
+		admin.Handle(*metricsPath, m)
+	}
+	return m.Wrap(rules.Handler(mux))
 }
 
 func newSite(mux *http.ServeMux, host string, content, goroot fs.FS) (*web.Site, error) {
-	fsys := unionFS{content, &fixSpecsFS{goroot}}
+	fsys := siteFS(content, goroot)
 	site := web.NewSite(fsys)
+	site.SetOutputCacheSize(*outputCache)
+	site.Funcs(template.FuncMap{
+		// googleCN reports whether the site is being served in China,
+		// where some Google services are unavailable.
+		// Go+ has no separate site for China.
+		"googleCN": func() bool { return false },
+	})
+	if *langs != "" {
+		site.SetLanguages(strings.Split(*langs, ",")...)
+	}
 	mux.Handle(host+"/", site)
+	mux.Handle(host+"/doc/codewalk/", codewalk.NewServer(fsys, site))
+
+	// Build the alias index now rather than on the first request
+	// for a missing page, reporting conflicting aliases.
+	for _, err := range site.CheckAliases() {
+		log.Printf("aliases: %v", err)
+	}
+
+	// Report stale codewalk step addresses now
+	// rather than when a reader clicks the step.
+	for _, err := range codewalk.Check(fsys, "doc/codewalk") {
+		log.Printf("codewalk: %v", err)
+	}
 	return site, nil
 }
+
+// siteFS returns the file system served by the site:
+// the content layered atop the Go root.
+func siteFS(content, goroot fs.FS) fs.FS {
+	return unionFS{content, &fixSpecsFS{goroot}}
+}
@@ -0,0 +1,46 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/fs"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/goplus/website/internal/webtest"
+)
+
+// A selfTester serves the results of running test scripts
+// against the site handler.
+// It reruns the scripts at most once per interval,
+// serving the cached results in between, so that
+// frequent polling does not load the site.
+type selfTester struct {
+	handler  http.Handler
+	scripts  fs.FS
+	interval time.Duration
+
+	mu      sync.Mutex // held while running the scripts
+	last    time.Time  // when the scripts last ran
+	results webtest.Results
+	err     error
+}
+
+func (s *selfTester) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	if s.last.IsZero() || time.Since(s.last) >= s.interval {
+		s.results, s.err = new(webtest.Runner).RunHandler(s.scripts, "*.txt", s.handler)
+		s.last = time.Now()
+	}
+	results, err := s.results, s.err
+	s.mu.Unlock()
+
+	if err != nil {
+		http.Error(w, "webtest: "+err.Error()+"\n", http.StatusInternalServerError)
+		return
+	}
+	results.ServeHTTP(w, req)
+}
@@ -0,0 +1,260 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	httpsAddr     = flag.String("https", "", "HTTPS service address (requires -tlscert and -tlskey, or -tlsdev)")
+	tlsCert       = flag.String("tlscert", "", "TLS certificate file")
+	tlsKey        = flag.String("tlskey", "", "TLS key file")
+	tlsDev        = flag.Bool("tlsdev", false, "serve HTTPS with a self-signed certificate, for development")
+	redirectHTTPS = flag.Bool("redirecthttps", false, "redirect HTTP requests to HTTPS instead of serving them (requires -https)")
+	unixSocket    = flag.String("unix", "", "Unix socket path to serve HTTP on, in addition to -http")
+	adminAddr     = flag.String("admin", "", "private HTTP address for operator endpoints such as -metrics and -selftest; do not expose it publicly")
+
+	readTimeout     = flag.Duration("readtimeout", 10*time.Second, "maximum duration for reading a request")
+	writeTimeout    = flag.Duration("writetimeout", 30*time.Second, "maximum duration for writing a response")
+	idleTimeout     = flag.Duration("idletimeout", 2*time.Minute, "maximum idle time for keep-alive connections")
+	shutdownTimeout = flag.Duration("shutdowntimeout", 15*time.Second, "maximum time to drain requests at shutdown")
+)
+
+// A listener is a server together with the listener it serves.
+type listener struct {
+	name string
+	srv  *http.Server
+	ln   net.Listener
+	tls  bool
+}
+
+// newServer returns an http.Server for handler using the configured timeouts.
+func newServer(handler http.Handler) *http.Server {
+	return &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: *readTimeout,
+		ReadTimeout:       *readTimeout,
+		WriteTimeout:      *writeTimeout,
+		IdleTimeout:       *idleTimeout,
+	}
+}
+
+// serve serves handler on the configured addresses,
+// and admin on the -admin address, until
+// the process receives SIGINT or SIGTERM, at which point
+// it stops accepting connections and waits up to -shutdowntimeout
+// for requests in progress to finish.
+func serve(handler, admin http.Handler) error {
+	var list []*listener
+
+	if *httpsAddr != "" {
+		cfg, err := tlsConfig()
+		if err != nil {
+			return err
+		}
+		ln, err := net.Listen("tcp", *httpsAddr)
+		if err != nil {
+			return err
+		}
+		srv := newServer(handler)
+		srv.TLSConfig = cfg
+		list = append(list, &listener{"https://" + *httpsAddr, srv, ln, true})
+	}
+
+	if *httpAddr != "" {
+		h := handler
+		if *redirectHTTPS {
+			h = httpsRedirect(*httpsAddr)
+		}
+		ln, err := net.Listen("tcp", *httpAddr)
+		if err != nil {
+			return err
+		}
+		list = append(list, &listener{"http://" + *httpAddr, newServer(h), ln, false})
+	}
+
+	if *unixSocket != "" {
+		if err := removeStaleSocket(*unixSocket); err != nil {
+			return err
+		}
+		ln, err := net.Listen("unix", *unixSocket)
+		if err != nil {
+			return err
+		}
+		list = append(list, &listener{"unix:" + *unixSocket, newServer(handler), ln, false})
+	}
+
+	if *adminAddr != "" {
+		ln, err := net.Listen("tcp", *adminAddr)
+		if err != nil {
+			return err
+		}
+		list = append(list, &listener{"admin http://" + *adminAddr, newServer(admin), ln, false})
+	}
+
+	errc := make(chan error, len(list))
+	for _, l := range list {
+		l := l
+		fmt.Fprintf(os.Stderr, "serving %s\n", l.name)
+		go func() {
+			var err error
+			if l.tls {
+				err = l.srv.ServeTLS(l.ln, "", "")
+			} else {
+				err = l.srv.Serve(l.ln)
+			}
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				err = fmt.Errorf("%s: %v", l.name, err)
+			}
+			errc <- err
+		}()
+	}
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+
+	var err error
+	select {
+	case sig := <-sigc:
+		log.Printf("received %v; shutting down", sig)
+	case err = <-errc:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+	for _, l := range list {
+		if e := l.srv.Shutdown(ctx); e != nil && err == nil {
+			err = fmt.Errorf("%s: shutdown: %v", l.name, e)
+		}
+	}
+	return err
+}
+
+// removeStaleSocket removes the Unix socket file at path if it was
+// left behind by an earlier server that is no longer running,
+// as shown by connections to it being refused.
+// It returns an error if another server is still listening on it.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSocket == 0 {
+		return nil
+	}
+	c, err := net.Dial("unix", path)
+	if err == nil {
+		c.Close()
+		return fmt.Errorf("unix:%s: socket in use by another server", path)
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return os.Remove(path)
+	}
+	return nil
+}
+
+// tlsConfig returns the TLS configuration for the HTTPS server.
+func tlsConfig() (*tls.Config, error) {
+	var cert tls.Certificate
+	var err error
+	switch {
+	case *tlsCert != "" || *tlsKey != "":
+		if *tlsCert == "" || *tlsKey == "" {
+			return nil, errors.New("-tlscert and -tlskey must be set together")
+		}
+		cert, err = tls.LoadX509KeyPair(*tlsCert, *tlsKey)
+	case *tlsDev:
+		cert, err = selfSignedCert(hostOf(*httpsAddr))
+	default:
+		return nil, errors.New("-https requires -tlscert and -tlskey, or -tlsdev")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// selfSignedCert returns a new self-signed certificate
+// valid for localhost and the given host.
+func selfSignedCert(host string) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"goporg development"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(30 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+	} else if host != "" && host != "localhost" {
+		tmpl.DNSNames = append(tmpl.DNSNames, host)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
+
+// httpsRedirect returns a handler redirecting each request
+// to the same URL on the HTTPS server at httpsAddr.
+// If httpsAddr is empty or uses the standard port 443,
+// the redirect omits the port.
+func httpsRedirect(httpsAddr string) http.Handler {
+	_, port, _ := net.SplitHostPort(httpsAddr)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := hostOf(r.Host)
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]" // IPv6 literal
+		}
+		if port != "" && port != "443" {
+			host += ":" + port
+		}
+		u := *r.URL
+		u.Scheme = "https"
+		u.Host = host
+		http.Redirect(w, r, u.String(), http.StatusMovedPermanently)
+	})
+}
+
+// hostOf returns the host in addr, which may or may not have a port.
+func hostOf(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
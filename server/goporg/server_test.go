@@ -0,0 +1,92 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/goplus/website/internal/webtest"
+)
+
+var update = flag.Bool("update", false, "update snapshot files in testdata instead of checking them")
+
+// testGoroot is a small stand-in for GOROOT,
+// holding the files that the test scripts expect to find there.
+var testGoroot = fstest.MapFS{
+	"VERSION": {Data: []byte("go1.16\n")},
+	"doc/go_spec.html": {Data: []byte(`<!--{
+	"Title": "The Go Programming Language Specification"
+}-->
+
+<h2 id="Introduction">Introduction</h2>
+`)},
+	"doc/go_mem.html": {Data: []byte(`<!--{
+	"Title": "The Go Memory Model"
+}-->
+
+<h2 id="Introduction">Introduction</h2>
+`)},
+	"src/fmt/print.go": {Data: []byte("package fmt\n")},
+}
+
+func TestWeb(t *testing.T) {
+	*accessLog = false
+	h := newHandler(os.DirFS("../../_content"), testGoroot, nil)
+	(&webtest.Runner{Update: *update}).TestHandler(t, "testdata/*.txt", h)
+}
+
+func TestSelfTester(t *testing.T) {
+	hits := 0
+	st := &selfTester{
+		handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			hits++
+			w.Write([]byte("hello"))
+		}),
+		scripts:  fstest.MapFS{"a.txt": {Data: []byte("GET /\nbody == hello\n")}},
+		interval: time.Hour,
+	}
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		st.ServeHTTP(rec, httptest.NewRequest("GET", "/_selftest", nil))
+		if rec.Code != 200 || rec.Body.String() != "ok\n" {
+			t.Fatalf("self-test #%d = %d %q, want 200 \"ok\\n\"", i, rec.Code, rec.Body.String())
+		}
+	}
+	if hits != 1 {
+		t.Errorf("scripts ran %d times within the interval, want 1", hits)
+	}
+}
+
+func TestRemoveStaleSocket(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "sock")
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	if err := removeStaleSocket(sock); err == nil {
+		t.Errorf("removeStaleSocket of live socket succeeded, want error")
+	}
+	if _, err := os.Lstat(sock); err != nil {
+		t.Fatalf("live socket removed: %v", err)
+	}
+
+	// Leave the socket file behind, as a crashed server would.
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	ln.Close()
+	if err := removeStaleSocket(sock); err != nil {
+		t.Errorf("removeStaleSocket of stale socket: %v", err)
+	}
+	if _, err := os.Lstat(sock); !os.IsNotExist(err) {
+		t.Errorf("stale socket not removed: %v", err)
+	}
+}